@@ -0,0 +1,67 @@
+package decay
+
+import (
+	"math"
+	"time"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// Curve returns the sigma of a rating after elapsed time has gone by without
+// playing, given its current sigma and the initial sigma of a new rating.
+// The result is capped by Decay, so curves do not have to.
+type Curve func(sigma, initial float64, elapsed time.Duration) float64
+
+// Linear returns a Curve that adds tau squared to the variance for every
+// period of inactivity, as if Tau was applied once per period
+func Linear(tau float64, period time.Duration) Curve {
+	return func(sigma, _ float64, elapsed time.Duration) float64 {
+		periods := float64(elapsed) / float64(period)
+		return math.Sqrt(sigma*sigma + tau*tau*periods)
+	}
+}
+
+// Exponential returns a Curve that moves the variance towards the one of a
+// new rating, closing half of the gap every halfLife
+func Exponential(halfLife time.Duration) Curve {
+	return func(sigma, initial float64, elapsed time.Duration) float64 {
+		remaining := math.Exp2(-float64(elapsed) / float64(halfLife))
+		gap := initial*initial - sigma*sigma
+		return math.Sqrt(initial*initial - gap*remaining)
+	}
+}
+
+// Decay inflates sigma according to a Curve, never past the sigma of a new
+// rating. It implements types.SigmaDecay.
+type Decay struct {
+	curve   Curve
+	initial float64
+}
+
+var _ types.SigmaDecay = (*Decay)(nil)
+
+// New returns a new Decay following curve, capped at the initial sigma
+// resolved from options
+func New(options *types.OpenSkillOptions, curve Curve) *Decay {
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	return &Decay{
+		curve:   curve,
+		initial: rating.NewWithOptions(options).Sigma,
+	}
+}
+
+// Decay returns r as it is at now. Ratings that never played, that played
+// after now or whose sigma is already at the cap are returned unchanged.
+func (d *Decay) Decay(r types.Rating, now time.Time) types.Rating {
+	if r.LastPlayed.IsZero() || !now.After(r.LastPlayed) || r.Sigma >= d.initial {
+		return r
+	}
+
+	sigma := d.curve(r.Sigma, d.initial, now.Sub(r.LastPlayed))
+	r.Sigma = math.Min(math.Max(sigma, r.Sigma), d.initial)
+	return r
+}
@@ -0,0 +1,79 @@
+package decay_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/decay"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const day = 24 * time.Hour
+
+// played returns a rating with the given sigma that last played at epoch
+func played(sigma float64) types.Rating {
+	return types.Rating{Mu: 25, Sigma: sigma, Z: 3, LastPlayed: epoch}
+}
+
+func TestLinearAddsVariancePerPeriod(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	d := decay.New(nil, decay.Linear(1, day))
+	is.Equal(d.Decay(played(3), epoch.Add(4*day)).Sigma, math.Sqrt(13))
+	is.Equal(d.Decay(played(3), epoch.Add(day/4)).Sigma, math.Sqrt(9.25))
+}
+
+func TestExponentialClosesHalfTheGapEveryHalfLife(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	d := decay.New(&types.OpenSkillOptions{Sigma: ptr.Float64(5)}, decay.Exponential(30*day))
+	is.Equal(d.Decay(played(3), epoch.Add(30*day)).Sigma, math.Sqrt(17))
+	is.Equal(d.Decay(played(3), epoch.Add(60*day)).Sigma, math.Sqrt(21))
+}
+
+func TestDecayIsCappedAtInitialSigma(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	d := decay.New(nil, decay.Linear(1, day))
+	initial := rating.New().Sigma
+	is.Equal(d.Decay(played(3), epoch.Add(1000*day)).Sigma, initial)
+
+	// Ratings that are already less certain than a new one are left alone
+	is.Equal(d.Decay(played(initial+1), epoch.Add(1000*day)).Sigma, initial+1)
+}
+
+func TestDecayLeavesRatingsWithoutElapsedTimeAlone(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	d := decay.New(nil, decay.Linear(1, day))
+	is.Equal(d.Decay(rating.New(), epoch), rating.New())
+	is.Equal(d.Decay(played(3), epoch), played(3))
+	is.Equal(d.Decay(played(3), epoch.Add(-day)), played(3))
+}
+
+func TestDecayIsLazy(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	d := decay.New(nil, decay.Exponential(10*day))
+	r := played(3)
+
+	// Reading never changes the stored rating, so every read decays from
+	// the last match rather than from the previous read
+	first := d.Decay(r, epoch.Add(10*day))
+	is.Equal(first.LastPlayed, epoch)
+	is.Equal(r, played(3))
+	is.Equal(d.Decay(r, epoch.Add(10*day)), first)
+	is.True(d.Decay(r, epoch.Add(20*day)).Sigma > first.Sigma)
+}
@@ -0,0 +1,83 @@
+// Package defaults resolves the hyperparameters of the rating system, it is
+// the only place where their default values are defined
+package defaults
+
+import "github.com/intinig/go-openskill/types"
+
+const (
+	// Mu is the default mean of a new rating
+	Mu = 25.0
+	// Z is the default number of standard deviations used by the ordinal
+	Z = 3
+	// Epsilon is the default small number that keeps the models stable
+	Epsilon = 0.0001
+	// DrawMargin is the default draw margin of the Thurstone-Mosteller models
+	DrawMargin = 0.1
+)
+
+// Values holds resolved hyperparameters
+type Values struct {
+	// Mu is options.Mu, or Mu
+	Mu float64
+	// Sigma is options.Sigma, or Mu / Z
+	Sigma float64
+	// Z is options.Z, or Z
+	Z int
+	// Beta is options.Beta, or Sigma / 2
+	Beta float64
+	// Epsilon is options.Epsilon, or Epsilon
+	Epsilon float64
+	// Kappa is options.Kappa, or Epsilon
+	Kappa float64
+	// DrawMargin is options.DrawMargin, or DrawMargin
+	DrawMargin float64
+}
+
+// Resolve returns the hyperparameters set in options, falling back to the
+// defaults for anything that is not set. Values that default to a function of
+// others, such as Sigma, are derived from the resolved values.
+func Resolve(options *types.OpenSkillOptions) Values {
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	v := Values{
+		Mu:         Mu,
+		Z:          Z,
+		Epsilon:    Epsilon,
+		DrawMargin: DrawMargin,
+	}
+
+	if options.Mu != nil {
+		v.Mu = *options.Mu
+	}
+
+	if options.Z != nil {
+		v.Z = *options.Z
+	}
+
+	v.Sigma = v.Mu / float64(v.Z)
+	if options.Sigma != nil {
+		v.Sigma = *options.Sigma
+	}
+
+	v.Beta = v.Sigma / 2
+	if options.Beta != nil {
+		v.Beta = *options.Beta
+	}
+
+	if options.Epsilon != nil {
+		v.Epsilon = *options.Epsilon
+	}
+
+	v.Kappa = v.Epsilon
+	if options.Kappa != nil {
+		v.Kappa = *options.Kappa
+	}
+
+	if options.DrawMargin != nil {
+		v.DrawMargin = *options.DrawMargin
+	}
+
+	return v
+}
@@ -0,0 +1,100 @@
+package defaults_test
+
+import (
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/defaults"
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestResolveDefaults(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	is.Equal(defaults.Resolve(nil), defaults.Values{
+		Mu:         25,
+		Sigma:      25 / 3.0,
+		Z:          3,
+		Beta:       25 / 6.0,
+		Epsilon:    0.0001,
+		Kappa:      0.0001,
+		DrawMargin: 0.1,
+	})
+}
+
+func TestResolveDerivesFromResolvedValues(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	v := defaults.Resolve(&types.OpenSkillOptions{
+		Mu:      ptr.Float64(30),
+		Z:       ptr.Int(2),
+		Epsilon: ptr.Float64(0.1),
+	})
+	is.Equal(v.Sigma, 15.0)
+	is.Equal(v.Beta, 7.5)
+	is.Equal(v.Kappa, 0.1)
+
+	v = defaults.Resolve(&types.OpenSkillOptions{Sigma: ptr.Float64(5)})
+	is.Equal(v.Mu, 25.0)
+	is.Equal(v.Beta, 2.5)
+}
+
+// options covers the ways the hyperparameters can be customised
+var options = map[string]*types.OpenSkillOptions{
+	"Nil":     nil,
+	"Default": {},
+	"Mu":      {Mu: ptr.Float64(30)},
+	"Z":       {Z: ptr.Int(2)},
+	"Sigma":   {Sigma: ptr.Float64(5)},
+	"Beta":    {Sigma: ptr.Float64(5), Beta: ptr.Float64(1)},
+	"Kappa":   {Epsilon: ptr.Float64(0.1), Kappa: ptr.Float64(0.2)},
+	"Draw":    {DrawMargin: ptr.Float64(1)},
+}
+
+func TestEveryPackageResolvesTheSameValues(t *testing.T) {
+	t.Parallel()
+
+	for name, o := range options {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			is := _is.New(t)
+			v := defaults.Resolve(o)
+
+			constants := models.NewConstants(o)
+			is.Equal(constants.Mu, v.Mu)
+			is.Equal(constants.Sigma, v.Sigma)
+			is.Equal(constants.Z, v.Z)
+			is.Equal(constants.Beta, v.Beta)
+			is.Equal(constants.Epsilon, v.Epsilon)
+			is.Equal(constants.Kappa, v.Kappa)
+			is.Equal(constants.DrawMargin, v.DrawMargin)
+
+			is.Equal(rating.NewWithOptions(o), types.Rating{Mu: v.Mu, Sigma: v.Sigma, Z: v.Z})
+		})
+	}
+}
+
+func TestRateAndPredictShareBeta(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	for name, o := range options {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			is := _is.New(t)
+
+			// Predicting with the beta the model rates with must not change
+			// anything, whatever else was customised
+			explicit := &types.OpenSkillOptions{Beta: ptr.Float64(models.NewPlackettLuce(o).Beta)}
+			is.Equal(rating.PredictWin(teams, o), rating.PredictWin(teams, explicit))
+			is.Equal(rating.PredictDraw(teams, o), rating.PredictDraw(teams, explicit))
+		})
+	}
+}
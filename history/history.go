@@ -0,0 +1,319 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+var (
+	// ErrMissingTimestamp is returned when a record without a timestamp is
+	// appended to a log
+	ErrMissingTimestamp = errors.New("openskill: record has no timestamp")
+	// ErrStaleCheckpoint is returned when a checkpoint does not match the
+	// records it is resumed with, for instance because older records were
+	// added after it was taken
+	ErrStaleCheckpoint = errors.New("openskill: checkpoint does not match records")
+)
+
+// Record is a match as it was played: the teams by player ID, its outcome and
+// a snapshot of the options it was rated with
+type Record struct {
+	// Seq is the position of the record in its Log, it breaks ties between
+	// records sharing the same timestamp
+	Seq uint64
+	// Timestamp is when the match was played, records are replayed in
+	// timestamp order and rated at their timestamp when their options do not
+	// set Now
+	Timestamp time.Time
+	// Teams holds the player IDs of each team
+	Teams [][]string
+	// Rank is the rank of each team, see types.OpenSkillOptions
+	Rank []int
+	// Score is the score of each team, see types.OpenSkillOptions
+	Score []int
+	// Scores is the float score of each team, see types.OpenSkillOptions
+	Scores []float64
+	// Weight is the weight of each player, see types.OpenSkillOptions
+	Weight [][]float64
+	// Options is a snapshot of the options the match was rated with, its Rank,
+	// Score, Scores and Weight are always nil since they live on the record
+	Options types.OpenSkillOptions
+}
+
+// NewRecord returns a new Record of a match, taking the outcome from options.
+// Options are copied, so changing them afterwards does not alter the record.
+func NewRecord(timestamp time.Time, teams [][]string, options *types.OpenSkillOptions) Record {
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	record := Record{
+		Timestamp: timestamp,
+		Teams:     copyTeams(teams),
+		Rank:      copyInts(options.Rank),
+		Score:     copyInts(options.Score),
+		Scores:    copyFloats(options.Scores),
+		Weight:    copyWeight(options.Weight),
+		Options:   snapshot(options),
+	}
+
+	return record
+}
+
+// options returns the options to rate the record with, built on top of base.
+// The match is rated at its timestamp unless base says otherwise, so that
+// decay and LastPlayed do not depend on when the replay runs.
+func (r Record) options(base types.OpenSkillOptions) *types.OpenSkillOptions {
+	base.Rank = r.Rank
+	base.Score = r.Score
+	base.Scores = r.Scores
+	base.Weight = r.Weight
+	if base.Now.IsZero() {
+		base.Now = r.Timestamp
+	}
+	return &base
+}
+
+// before reports whether r is replayed before other
+func (r Record) before(other Record) bool {
+	if r.Timestamp.Equal(other.Timestamp) {
+		return r.Seq < other.Seq
+	}
+	return r.Timestamp.Before(other.Timestamp)
+}
+
+// Log is an append-only list of records, safe for concurrent use
+type Log struct {
+	mu      sync.RWMutex
+	records []Record
+}
+
+// NewLog returns a new empty Log
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Append adds a record to the log and returns it with its Seq set
+func (l *Log) Append(record Record) (Record, error) {
+	if record.Timestamp.IsZero() {
+		return Record{}, ErrMissingTimestamp
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.Seq = uint64(len(l.records) + 1)
+	record.Teams = copyTeams(record.Teams)
+	record.Rank = copyInts(record.Rank)
+	record.Score = copyInts(record.Score)
+	record.Scores = copyFloats(record.Scores)
+	record.Weight = copyWeight(record.Weight)
+	record.Options = snapshot(&record.Options)
+	l.records = append(l.records, record)
+
+	return record, nil
+}
+
+// Records returns a copy of all the records in the log, in append order
+func (l *Log) Records() []Record {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append([]Record(nil), l.records...)
+}
+
+// Len returns the number of records in the log
+func (l *Log) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.records)
+}
+
+// Checkpoint is the state of a replay after a number of records, it can be
+// used to resume the replay later on
+type Checkpoint struct {
+	// Ratings holds the rating of every player seen so far
+	Ratings map[string]types.Rating
+	// Applied is the number of records replayed so far
+	Applied int
+	// Timestamp and Seq identify the last replayed record
+	Timestamp time.Time
+	Seq       uint64
+}
+
+// ReplayOptions controls a replay
+type ReplayOptions struct {
+	// From is the checkpoint to resume from, the replay starts from scratch
+	// when it is nil. It is never modified.
+	From *Checkpoint
+	// Until stops the replay before the first record at or after it, all
+	// records are replayed when it is zero
+	Until time.Time
+	// Override returns the options a record is rated with, which is how
+	// ratings are re-derived after a rules change. The snapshot stored in the
+	// record is used when it is nil. The outcome always comes from the record.
+	Override func(Record) *types.OpenSkillOptions
+}
+
+// Replay folds records through rating.Rate in timestamp order and returns the
+// resulting checkpoint. Replaying the same records always yields the exact
+// same ratings, and resuming from a checkpoint yields the same ratings as
+// replaying everything at once.
+func Replay(records []Record, options *ReplayOptions) (*Checkpoint, error) {
+	if options == nil {
+		options = &ReplayOptions{}
+	}
+
+	sorted := append([]Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].before(sorted[j])
+	})
+
+	checkpoint := &Checkpoint{
+		Ratings: make(map[string]types.Rating),
+	}
+
+	if options.From != nil {
+		if err := resumable(sorted, options.From); err != nil {
+			return nil, err
+		}
+
+		for id, r := range options.From.Ratings {
+			checkpoint.Ratings[id] = r
+		}
+		checkpoint.Applied = options.From.Applied
+		checkpoint.Timestamp = options.From.Timestamp
+		checkpoint.Seq = options.From.Seq
+	}
+
+	for _, record := range sorted[checkpoint.Applied:] {
+		if !options.Until.IsZero() && !record.Timestamp.Before(options.Until) {
+			break
+		}
+
+		base := record.Options
+		if options.Override != nil {
+			if override := options.Override(record); override != nil {
+				base = *override
+			}
+		}
+		recordOptions := record.options(base)
+
+		teams := make([]types.Team, len(record.Teams))
+		for i, team := range record.Teams {
+			teams[i] = make(types.Team, len(team))
+			for j, id := range team {
+				r, ok := checkpoint.Ratings[id]
+				if !ok {
+					r = rating.NewWithOptions(recordOptions)
+				}
+				teams[i][j] = r
+			}
+		}
+
+		rated, err := rating.RateE(teams, recordOptions)
+		if err != nil {
+			return nil, fmt.Errorf("record %d at %s: %w", record.Seq, record.Timestamp.Format(time.RFC3339Nano), err)
+		}
+
+		for i, team := range record.Teams {
+			for j, id := range team {
+				checkpoint.Ratings[id] = rated[i][j]
+			}
+		}
+
+		checkpoint.Applied++
+		checkpoint.Timestamp = record.Timestamp
+		checkpoint.Seq = record.Seq
+	}
+
+	return checkpoint, nil
+}
+
+// resumable checks that checkpoint was taken on the same sorted records
+func resumable(sorted []Record, checkpoint *Checkpoint) error {
+	if checkpoint.Applied == 0 {
+		return nil
+	}
+
+	if checkpoint.Applied > len(sorted) {
+		return fmt.Errorf("%w: %d records applied but only %d available", ErrStaleCheckpoint, checkpoint.Applied, len(sorted))
+	}
+
+	last := sorted[checkpoint.Applied-1]
+	if !last.Timestamp.Equal(checkpoint.Timestamp) || last.Seq != checkpoint.Seq {
+		return fmt.Errorf("%w: record %d is not the last one applied", ErrStaleCheckpoint, last.Seq)
+	}
+
+	return nil
+}
+
+// snapshot returns a deep copy of options without the outcome of the match
+func snapshot(options *types.OpenSkillOptions) types.OpenSkillOptions {
+	s := *options
+	s.Rank = nil
+	s.Score = nil
+	s.Scores = nil
+	s.Weight = nil
+	s.Z = copyPtr(options.Z)
+	s.Mu = copyPtr(options.Mu)
+	s.Sigma = copyPtr(options.Sigma)
+	s.Epsilon = copyPtr(options.Epsilon)
+	s.DrawMargin = copyPtr(options.DrawMargin)
+	s.Beta = copyPtr(options.Beta)
+	s.Tau = copyPtr(options.Tau)
+	s.Kappa = copyPtr(options.Kappa)
+	s.Margin = copyPtr(options.Margin)
+	return s
+}
+
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func copyInts(src []int) []int {
+	if src == nil {
+		return nil
+	}
+	return append([]int(nil), src...)
+}
+
+func copyFloats(src []float64) []float64 {
+	if src == nil {
+		return nil
+	}
+	return append([]float64(nil), src...)
+}
+
+func copyTeams(src [][]string) [][]string {
+	if src == nil {
+		return nil
+	}
+	dest := make([][]string, len(src))
+	for i, team := range src {
+		dest[i] = append([]string(nil), team...)
+	}
+	return dest
+}
+
+func copyWeight(src [][]float64) [][]float64 {
+	if src == nil {
+		return nil
+	}
+	dest := make([][]float64, len(src))
+	for i, weight := range src {
+		dest[i] = append([]float64(nil), weight...)
+	}
+	return dest
+}
@@ -0,0 +1,253 @@
+package history_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/decay"
+	"github.com/intinig/go-openskill/history"
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// matches returns a small set of records, appended out of timestamp order
+func matches(t *testing.T) *history.Log {
+	t.Helper()
+	log := history.NewLog()
+	for _, record := range []history.Record{
+		history.NewRecord(epoch.Add(2*time.Hour), [][]string{{"a"}, {"c"}}, &types.OpenSkillOptions{
+			Score: []int{1, 3},
+		}),
+		history.NewRecord(epoch, [][]string{{"a", "b"}, {"c", "d"}}, &types.OpenSkillOptions{
+			Rank: []int{2, 1},
+		}),
+		history.NewRecord(epoch.Add(time.Hour), [][]string{{"b"}, {"c"}, {"d"}}, &types.OpenSkillOptions{
+			Weight: [][]float64{{1}, {0.5}, {1}},
+		}),
+	} {
+		if _, err := log.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return log
+}
+
+// expected rates the records of matches by hand, in timestamp order and at
+// their timestamp
+func expected(model types.RatingModel, sigmaDecay types.SigmaDecay) map[string]types.Rating {
+	r := rating.New()
+
+	m1 := rating.Rate([]types.Team{{r, r}, {r, r}}, &types.OpenSkillOptions{
+		Rank:  []int{2, 1},
+		Model: model,
+		Now:   epoch,
+		Decay: sigmaDecay,
+	})
+	a, b, c, d := m1[0][0], m1[0][1], m1[1][0], m1[1][1]
+
+	m2 := rating.Rate([]types.Team{{b}, {c}, {d}}, &types.OpenSkillOptions{
+		Weight: [][]float64{{1}, {0.5}, {1}},
+		Model:  model,
+		Now:    epoch.Add(time.Hour),
+		Decay:  sigmaDecay,
+	})
+	b, c, d = m2[0][0], m2[1][0], m2[2][0]
+
+	m3 := rating.Rate([]types.Team{{a}, {c}}, &types.OpenSkillOptions{
+		Score: []int{1, 3},
+		Model: model,
+		Now:   epoch.Add(2 * time.Hour),
+		Decay: sigmaDecay,
+	})
+	a, c = m3[0][0], m3[1][0]
+
+	return map[string]types.Rating{"a": a, "b": b, "c": c, "d": d}
+}
+
+func TestNewRecordSnapshotsOptions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	options := &types.OpenSkillOptions{
+		Mu:     ptr.Float64(30),
+		Rank:   []int{2, 1},
+		Weight: [][]float64{{1}, {0.5}},
+	}
+	teams := [][]string{{"a"}, {"b"}}
+	record := history.NewRecord(epoch, teams, options)
+
+	*options.Mu = 10
+	options.Rank[0] = 5
+	options.Weight[1][0] = 1
+	teams[0][0] = "z"
+
+	is.Equal(*record.Options.Mu, 30.0)
+	is.Equal(record.Rank, []int{2, 1})
+	is.Equal(record.Weight, [][]float64{{1}, {0.5}})
+	is.Equal(record.Teams, [][]string{{"a"}, {"b"}})
+	is.Equal(record.Options.Rank, nil)
+}
+
+func TestNewRecordSnapshotsFloatScores(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	options := &types.OpenSkillOptions{
+		Scores:        []float64{61.2, 59.8},
+		LowerIsBetter: true,
+		Margin:        ptr.Float64(0.5),
+	}
+	record := history.NewRecord(epoch, [][]string{{"a"}, {"b"}}, options)
+
+	options.Scores[0] = 0
+	*options.Margin = 2
+
+	is.Equal(record.Scores, []float64{61.2, 59.8})
+	is.Equal(record.Options.Scores, nil)
+	is.True(record.Options.LowerIsBetter)
+	is.Equal(*record.Options.Margin, 0.5)
+}
+
+func TestLogAppendAssignsSequence(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	log := history.NewLog()
+	first, err := log.Append(history.NewRecord(epoch, [][]string{{"a"}, {"b"}}, nil))
+	is.NoErr(err)
+	second, err := log.Append(history.NewRecord(epoch, [][]string{{"a"}, {"b"}}, nil))
+	is.NoErr(err)
+
+	is.Equal(first.Seq, uint64(1))
+	is.Equal(second.Seq, uint64(2))
+	is.Equal(log.Len(), 2)
+	is.Equal(log.Records()[1], second)
+
+	_, err = log.Append(history.Record{Teams: [][]string{{"a"}, {"b"}}})
+	is.True(errors.Is(err, history.ErrMissingTimestamp))
+	is.Equal(log.Len(), 2)
+}
+
+func TestReplayFollowsTimestampOrder(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	checkpoint, err := history.Replay(matches(t).Records(), nil)
+	is.NoErr(err)
+	is.Equal(checkpoint.Ratings, expected(nil, nil))
+	is.Equal(checkpoint.Applied, 3)
+	is.Equal(checkpoint.Timestamp, epoch.Add(2*time.Hour))
+	is.Equal(checkpoint.Seq, uint64(1))
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	records := matches(t).Records()
+	first, err := history.Replay(records, nil)
+	is.NoErr(err)
+	second, err := history.Replay(records, nil)
+	is.NoErr(err)
+	is.Equal(first, second)
+}
+
+func TestReplayResumesFromCheckpoint(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	records := matches(t).Records()
+	partial, err := history.Replay(records, &history.ReplayOptions{
+		Until: epoch.Add(time.Hour),
+	})
+	is.NoErr(err)
+	is.Equal(partial.Applied, 1)
+
+	before := len(partial.Ratings)
+	resumed, err := history.Replay(records, &history.ReplayOptions{
+		From: partial,
+	})
+	is.NoErr(err)
+
+	full, err := history.Replay(records, nil)
+	is.NoErr(err)
+	is.Equal(resumed, full)
+
+	// The checkpoint we resumed from is left untouched
+	is.Equal(partial.Applied, 1)
+	is.Equal(len(partial.Ratings), before)
+}
+
+func TestReplayRejectsStaleCheckpoints(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	log := matches(t)
+	checkpoint, err := history.Replay(log.Records(), &history.ReplayOptions{
+		Until: epoch.Add(90 * time.Minute),
+	})
+	is.NoErr(err)
+
+	// A match from the past shows up after the checkpoint was taken
+	_, err = log.Append(history.NewRecord(epoch.Add(time.Minute), [][]string{{"a"}, {"d"}}, nil))
+	is.NoErr(err)
+
+	_, err = history.Replay(log.Records(), &history.ReplayOptions{
+		From: checkpoint,
+	})
+	is.True(errors.Is(err, history.ErrStaleCheckpoint))
+}
+
+func TestReplayOverridesOptionsForRulesChanges(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	checkpoint, err := history.Replay(matches(t).Records(), &history.ReplayOptions{
+		Override: func(history.Record) *types.OpenSkillOptions {
+			return &types.OpenSkillOptions{Model: model}
+		},
+	})
+	is.NoErr(err)
+	is.Equal(checkpoint.Ratings, expected(model, nil))
+}
+
+func TestReplayRatesRecordsAtTheirTimestamp(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// Overridden options carry no Now, the replay must not fall back on the
+	// wall clock
+	sigmaDecay := decay.New(nil, decay.Linear(0.5, time.Hour))
+	checkpoint, err := history.Replay(matches(t).Records(), &history.ReplayOptions{
+		Override: func(history.Record) *types.OpenSkillOptions {
+			return &types.OpenSkillOptions{Decay: sigmaDecay}
+		},
+	})
+	is.NoErr(err)
+	is.Equal(checkpoint.Ratings, expected(nil, sigmaDecay))
+	is.True(checkpoint.Ratings["d"] != expected(nil, nil)["d"]) // d decayed between matches
+
+	is.Equal(checkpoint.Ratings["a"].LastPlayed, epoch.Add(2*time.Hour))
+	is.Equal(checkpoint.Ratings["b"].LastPlayed, epoch.Add(time.Hour))
+	is.Equal(checkpoint.Ratings["d"].LastPlayed, epoch.Add(time.Hour))
+}
+
+func TestReplayReportsInvalidRecords(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	_, err := history.Replay([]history.Record{
+		history.NewRecord(epoch, [][]string{{"a"}, {"b"}}, &types.OpenSkillOptions{
+			Rank: []int{1},
+		}),
+	}, nil)
+	is.True(errors.Is(err, rating.ErrRankLengthMismatch))
+}
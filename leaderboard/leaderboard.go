@@ -0,0 +1,257 @@
+// Package leaderboard keeps players ranked by rating as matches are played
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+var (
+	// ErrInvalidScore is returned when the score of a player is not a number
+	ErrInvalidScore = errors.New("openskill: invalid score")
+	// ErrShapeMismatch is returned when player IDs and ratings do not have
+	// the same shape
+	ErrShapeMismatch = errors.New("openskill: ids and teams do not match")
+)
+
+// Score turns a rating into the value players are ranked by, higher is better
+type Score func(r types.Rating) float64
+
+// Options configures a Leaderboard
+type Options struct {
+	// Score is what players are ranked by, the default value is
+	// rating.Ordinal
+	Score Score
+	// ProvisionalSigma is the sigma above which players are provisional:
+	// they are kept but not ranked until they have played enough to bring
+	// their sigma down. The default value of 0 ranks every player.
+	ProvisionalSigma float64
+}
+
+// Entry is a player on a Leaderboard
+type Entry struct {
+	// ID identifies the player
+	ID string
+	// Rating is the rating of the player
+	Rating types.Rating
+	// Score is what the player is ranked by
+	Score float64
+	// Rank is the position of the player starting from 1, players with the
+	// same score share the same rank. It is 0 for provisional players.
+	Rank int
+	// Provisional reports whether the sigma of the player is still above
+	// Options.ProvisionalSigma
+	Provisional bool
+}
+
+// Leaderboard keeps players ranked by score. Updating a player, finding their
+// rank and reading a page all take O(log n) time, plus the size of the page.
+// It is safe for concurrent use.
+type Leaderboard struct {
+	mu      sync.RWMutex
+	options Options
+	players map[string]*node
+	root    *node
+}
+
+// New returns a new empty Leaderboard
+func New(options *Options) *Leaderboard {
+	if options == nil {
+		options = &Options{}
+	}
+
+	o := *options
+	if o.Score == nil {
+		o.Score = rating.Ordinal
+	}
+
+	return &Leaderboard{
+		options: o,
+		players: make(map[string]*node),
+	}
+}
+
+// Set adds a player or updates their rating
+func (l *Leaderboard) Set(id string, r types.Rating) error {
+	if err := rating.Validate([]types.Team{{r}}, nil); err != nil {
+		return err
+	}
+
+	score := l.options.Score(r)
+	if math.IsNaN(score) {
+		return fmt.Errorf("%w: %s", ErrInvalidScore, id)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.set(id, r, score)
+
+	return nil
+}
+
+// Update sets the ratings returned by rating.Rate, ids holding the ID of each
+// player in the same shape as teams. Nothing is updated when any rating is
+// invalid.
+func (l *Leaderboard) Update(ids [][]string, teams []types.Team) error {
+	if len(ids) != len(teams) {
+		return fmt.Errorf("%w: %d teams of ids for %d teams", ErrShapeMismatch, len(ids), len(teams))
+	}
+
+	scores := make([][]float64, len(teams))
+	for i, team := range teams {
+		if len(ids[i]) != len(team) {
+			return fmt.Errorf("%w: team %d has %d ids for %d players", ErrShapeMismatch, i, len(ids[i]), len(team))
+		}
+
+		scores[i] = make([]float64, len(team))
+		for j, r := range team {
+			if err := rating.Validate([]types.Team{{r}}, nil); err != nil {
+				return err
+			}
+			scores[i][j] = l.options.Score(r)
+			if math.IsNaN(scores[i][j]) {
+				return fmt.Errorf("%w: %s", ErrInvalidScore, ids[i][j])
+			}
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, team := range teams {
+		for j, r := range team {
+			l.set(ids[i][j], r, scores[i][j])
+		}
+	}
+
+	return nil
+}
+
+// set stores a player, moving them in the tree when they are ranked
+func (l *Leaderboard) set(id string, r types.Rating, score float64) {
+	if n, ok := l.players[id]; ok && !n.provisional {
+		l.root = remove(l.root, n)
+	}
+
+	n := &node{
+		id:          id,
+		rating:      r,
+		score:       score,
+		provisional: l.options.ProvisionalSigma > 0 && r.Sigma > l.options.ProvisionalSigma,
+		priority:    priority(),
+		size:        1,
+	}
+	l.players[id] = n
+
+	if !n.provisional {
+		l.root = insert(l.root, n)
+	}
+}
+
+// Delete removes a player and reports whether they were on the leaderboard
+func (l *Leaderboard) Delete(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n, ok := l.players[id]
+	if !ok {
+		return false
+	}
+
+	if !n.provisional {
+		l.root = remove(l.root, n)
+	}
+	delete(l.players, id)
+
+	return true
+}
+
+// Get returns a player and whether they were found
+func (l *Leaderboard) Get(id string) (Entry, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	n, ok := l.players[id]
+	if !ok {
+		return Entry{}, false
+	}
+
+	return l.entry(n), true
+}
+
+// Rank returns the rank of a player, starting from 1, and whether they are
+// ranked. Missing and provisional players are not.
+func (l *Leaderboard) Rank(id string) (int, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	n, ok := l.players[id]
+	if !ok || n.provisional {
+		return 0, false
+	}
+
+	return l.rank(n.score), true
+}
+
+// Len returns the number of ranked players
+func (l *Leaderboard) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return size(l.root)
+}
+
+// Page returns up to limit ranked players, from the best one down, skipping
+// the first offset
+func (l *Leaderboard) Page(offset, limit int) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || offset >= size(l.root) {
+		return nil
+	}
+
+	entries := make([]Entry, 0, min(limit, size(l.root)-offset))
+	walk(l.root, offset, limit, func(n *node) {
+		entry := Entry{ID: n.id, Rating: n.rating, Score: n.score}
+		if len(entries) == 0 {
+			entry.Rank = l.rank(n.score)
+		} else if previous := entries[len(entries)-1]; previous.Score == n.score {
+			entry.Rank = previous.Rank
+		} else {
+			entry.Rank = offset + len(entries) + 1
+		}
+		entries = append(entries, entry)
+	})
+
+	return entries
+}
+
+// entry returns the Entry of a player
+func (l *Leaderboard) entry(n *node) Entry {
+	entry := Entry{
+		ID:          n.id,
+		Rating:      n.rating,
+		Score:       n.score,
+		Provisional: n.provisional,
+	}
+	if !n.provisional {
+		entry.Rank = l.rank(n.score)
+	}
+	return entry
+}
+
+// rank returns the rank of a score: one more than the number of ranked
+// players with a higher score
+func (l *Leaderboard) rank(score float64) int {
+	return above(l.root, score) + 1
+}
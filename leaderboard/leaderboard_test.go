@@ -0,0 +1,263 @@
+package leaderboard_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/leaderboard"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+func player(mu, sigma float64) types.Rating {
+	return rating.NewWithOptions(&types.OpenSkillOptions{
+		Mu:    ptr.Float64(mu),
+		Sigma: ptr.Float64(sigma),
+	})
+}
+
+func ids(entries []leaderboard.Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestLeaderboardRanksByOrdinal(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(nil)
+	is.NoErr(l.Set("a", player(30, 8)))
+	is.NoErr(l.Set("b", player(28, 1)))
+	is.NoErr(l.Set("c", player(20, 1)))
+
+	is.Equal(ids(l.Page(0, 10)), []string{"b", "c", "a"})
+
+	rank, ok := l.Rank("a")
+	is.True(ok)
+	is.Equal(rank, 3)
+
+	entry, ok := l.Get("b")
+	is.True(ok)
+	is.Equal(entry, leaderboard.Entry{ID: "b", Rating: player(28, 1), Score: 25, Rank: 1})
+}
+
+func TestLeaderboardUpdate(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(nil)
+	a, b := rating.New(), rating.New()
+	is.NoErr(l.Set("a", a))
+	is.NoErr(l.Set("b", b))
+
+	// Equal scores share a rank, ties are listed by ID
+	is.Equal(l.Page(0, 10)[1].Rank, 1)
+
+	teams := rating.Rate([]types.Team{{a}, {b}}, &types.OpenSkillOptions{Rank: []int{2, 1}})
+	is.NoErr(l.Update([][]string{{"a"}, {"b"}}, teams))
+
+	is.Equal(ids(l.Page(0, 10)), []string{"b", "a"})
+	entry, _ := l.Get("a")
+	is.Equal(entry.Rating, teams[0][0])
+	is.Equal(entry.Rank, 2)
+	is.Equal(l.Len(), 2)
+}
+
+func TestLeaderboardProvisional(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(&leaderboard.Options{ProvisionalSigma: 5})
+	is.NoErr(l.Set("new", player(40, 8)))
+	is.NoErr(l.Set("known", player(25, 2)))
+
+	is.Equal(ids(l.Page(0, 10)), []string{"known"})
+	is.Equal(l.Len(), 1)
+
+	_, ok := l.Rank("new")
+	is.True(!ok)
+
+	entry, ok := l.Get("new")
+	is.True(ok)
+	is.True(entry.Provisional)
+	is.Equal(entry.Rank, 0)
+
+	// Once sigma comes down the player is ranked
+	is.NoErr(l.Set("new", player(40, 4)))
+	is.Equal(ids(l.Page(0, 10)), []string{"new", "known"})
+
+	// And leaves the ranking again if it goes back up
+	is.NoErr(l.Set("new", player(40, 6)))
+	is.Equal(ids(l.Page(0, 10)), []string{"known"})
+}
+
+func TestLeaderboardCustomScore(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(&leaderboard.Options{
+		Score: func(r types.Rating) float64 { return r.Mu },
+	})
+	is.NoErr(l.Set("a", player(30, 8)))
+	is.NoErr(l.Set("b", player(28, 1)))
+
+	is.Equal(ids(l.Page(0, 10)), []string{"a", "b"})
+	is.Equal(l.Page(0, 1)[0].Score, 30.0)
+}
+
+func TestLeaderboardDelete(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(&leaderboard.Options{ProvisionalSigma: 5})
+	is.NoErr(l.Set("a", player(30, 1)))
+	is.NoErr(l.Set("b", player(20, 1)))
+	is.NoErr(l.Set("c", player(20, 8)))
+
+	is.True(l.Delete("a"))
+	is.True(l.Delete("c"))
+	is.True(!l.Delete("a"))
+
+	_, ok := l.Get("a")
+	is.True(!ok)
+	rank, _ := l.Rank("b")
+	is.Equal(rank, 1)
+	is.Equal(l.Len(), 1)
+}
+
+func TestLeaderboardPage(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(nil)
+	for i := 0; i < 10; i++ {
+		is.NoErr(l.Set(fmt.Sprintf("p%d", i), player(float64(30-i/2), 1)))
+	}
+
+	// Pairs of players share a score, and a rank
+	page := l.Page(3, 4)
+	is.Equal(ids(page), []string{"p3", "p4", "p5", "p6"})
+	is.Equal([]int{page[0].Rank, page[1].Rank, page[2].Rank, page[3].Rank}, []int{3, 5, 5, 7})
+
+	is.Equal(len(l.Page(8, 10)), 2)
+	is.Equal(len(l.Page(10, 10)), 0)
+	is.Equal(len(l.Page(0, 0)), 0)
+	is.Equal(ids(l.Page(-1, 1)), []string{"p0"})
+}
+
+func TestLeaderboardMatchesSorting(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rand.New(rand.NewPCG(1, 1))
+	l := leaderboard.New(&leaderboard.Options{ProvisionalSigma: 6})
+	ratings := make(map[string]types.Rating)
+
+	for step := 0; step < 3000; step++ {
+		id := fmt.Sprintf("p%d", r.IntN(200))
+		if r.IntN(10) == 0 {
+			l.Delete(id)
+			delete(ratings, id)
+			continue
+		}
+
+		// Few distinct values so that ties are common
+		rt := player(float64(15+r.IntN(20)), float64(1+r.IntN(7)))
+		is.NoErr(l.Set(id, rt))
+		ratings[id] = rt
+	}
+
+	type ranked struct {
+		id    string
+		score float64
+	}
+	var want []ranked
+	for id, rt := range ratings {
+		if rt.Sigma <= 6 {
+			want = append(want, ranked{id, rating.Ordinal(rt)})
+		}
+	}
+	sort.Slice(want, func(i, j int) bool {
+		if want[i].score != want[j].score {
+			return want[i].score > want[j].score
+		}
+		return want[i].id < want[j].id
+	})
+
+	is.Equal(l.Len(), len(want))
+	page := l.Page(0, len(want))
+	for k, w := range want {
+		is.Equal(page[k].ID, w.id)
+
+		rank := 1
+		for _, other := range want {
+			if other.score > w.score {
+				rank++
+			}
+		}
+		is.Equal(page[k].Rank, rank)
+
+		got, ok := l.Rank(w.id)
+		is.True(ok)
+		is.Equal(got, rank)
+	}
+
+	// Any page agrees with the whole list
+	for offset := 0; offset < len(want); offset += 37 {
+		is.Equal(l.Page(offset, 10), page[offset:min(offset+10, len(page))])
+	}
+}
+
+func TestLeaderboardEmptyID(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(nil)
+	is.NoErr(l.Set("", player(25, 1)))
+	is.NoErr(l.Set("a", player(25, 1)))
+	is.NoErr(l.Set("b", player(30, 1)))
+
+	// The empty ID shares the rank of its score like any other
+	rank, ok := l.Rank("")
+	is.True(ok)
+	is.Equal(rank, 2)
+	rank, _ = l.Rank("a")
+	is.Equal(rank, 2)
+	rank, _ = l.Rank("b")
+	is.Equal(rank, 1)
+
+	is.Equal(ids(l.Page(0, 10)), []string{"b", "", "a"})
+	is.Equal([]int{l.Page(0, 10)[1].Rank, l.Page(0, 10)[2].Rank}, []int{2, 2})
+}
+
+func TestLeaderboardErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	l := leaderboard.New(nil)
+	is.True(errors.Is(l.Set("a", types.Rating{Mu: 25, Sigma: -1}), rating.ErrInvalidSigma))
+
+	nan := leaderboard.New(&leaderboard.Options{
+		Score: func(types.Rating) float64 { return math.NaN() },
+	})
+	is.True(errors.Is(nan.Set("a", rating.New()), leaderboard.ErrInvalidScore))
+
+	teams := []types.Team{{rating.New()}, {rating.New()}}
+	is.True(errors.Is(l.Update([][]string{{"a"}}, teams), leaderboard.ErrShapeMismatch))
+	is.True(errors.Is(l.Update([][]string{{"a"}, {"b", "c"}}, teams), leaderboard.ErrShapeMismatch))
+
+	// A bad rating leaves everyone as they were
+	teams[1][0].Sigma = 0
+	is.True(errors.Is(l.Update([][]string{{"a"}, {"b"}}, teams), rating.ErrInvalidSigma))
+	is.Equal(l.Len(), 0)
+}
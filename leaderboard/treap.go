@@ -0,0 +1,151 @@
+package leaderboard
+
+import (
+	"math/rand/v2"
+
+	"github.com/intinig/go-openskill/types"
+)
+
+// node is a player in a treap: a binary search tree ordered by score, from
+// the highest down, then by ID, that stays balanced by keeping every node's
+// random priority above the ones of its children. Each node knows the size of
+// its subtree, which is what makes finding positions fast.
+type node struct {
+	id          string
+	rating      types.Rating
+	score       float64
+	provisional bool
+
+	priority    uint64
+	size        int
+	left, right *node
+}
+
+func priority() uint64 {
+	return rand.Uint64()
+}
+
+func size(n *node) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node) update() {
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+// less reports whether a player with score and id ranks ahead of n
+func less(score float64, id string, n *node) bool {
+	if score != n.score {
+		return score > n.score
+	}
+	return id < n.id
+}
+
+// split splits the tree rooted at n into the nodes ranked ahead of score and
+// id, and the rest
+func split(n *node, score float64, id string) (*node, *node) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if less(score, id, n) {
+		left, right := split(n.left, score, id)
+		n.left = right
+		n.update()
+		return left, n
+	}
+
+	left, right := split(n.right, score, id)
+	n.right = left
+	n.update()
+	return n, right
+}
+
+// merge joins two trees, every node of a being ranked ahead of every node of b
+func merge(a, b *node) *node {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	default:
+		b.left = merge(a, b.left)
+		b.update()
+		return b
+	}
+}
+
+// insert adds n to the tree rooted at root and returns the new root
+func insert(root, n *node) *node {
+	left, right := split(root, n.score, n.id)
+	return merge(merge(left, n), right)
+}
+
+// remove removes n from the tree rooted at root and returns the new root
+func remove(root, n *node) *node {
+	if root == nil {
+		return nil
+	}
+
+	if root == n {
+		return merge(root.left, root.right)
+	}
+
+	if less(n.score, n.id, root) {
+		root.left = remove(root.left, n)
+	} else {
+		root.right = remove(root.right, n)
+	}
+	root.update()
+	return root
+}
+
+// above returns the number of nodes with a higher score than score, whatever
+// their id
+func above(n *node, score float64) int {
+	count := 0
+	for n != nil {
+		if n.score <= score {
+			n = n.left
+		} else {
+			count += size(n.left) + 1
+			n = n.right
+		}
+	}
+	return count
+}
+
+// walk calls fn on up to limit nodes in order, skipping the first skip, and
+// returns how many more nodes fn may still be called on
+func walk(n *node, skip, limit int, fn func(*node)) int {
+	if n == nil || limit == 0 {
+		return limit
+	}
+
+	if skip < size(n.left) {
+		limit = walk(n.left, skip, limit, fn)
+		skip = 0
+	} else {
+		skip -= size(n.left)
+	}
+
+	if limit == 0 {
+		return 0
+	}
+
+	if skip == 0 {
+		fn(n)
+		limit--
+	} else {
+		skip--
+	}
+
+	return walk(n.right, skip, limit, fn)
+}
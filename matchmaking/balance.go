@@ -0,0 +1,215 @@
+// Package matchmaking builds fair matches out of rated players
+package matchmaking
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// ExactLimit is the largest lobby Balance searches exhaustively, larger
+// lobbies are balanced with a local search
+const ExactLimit = 12
+
+// ErrInvalidTeamSizes is returned when the team sizes are not positive or do
+// not add up to the number of players
+var ErrInvalidTeamSizes = errors.New("openskill: invalid team sizes")
+
+// Partition is a split of a lobby into teams
+type Partition struct {
+	// Teams holds, for each team, the indexes of its players in the lobby, in
+	// ascending order
+	Teams [][]int
+	// Quality is the match quality of the teams, see rating.MatchQuality
+	Quality float64
+}
+
+// Ratings returns the teams of the partition, built from the lobby it was
+// computed for
+func (p Partition) Ratings(players []types.Rating) []types.Team {
+	teams := make([]types.Team, len(p.Teams))
+	for i, team := range p.Teams {
+		teams[i] = make(types.Team, len(team))
+		for j, index := range team {
+			teams[i][j] = players[index]
+		}
+	}
+
+	return teams
+}
+
+// Balance splits players into teams of the given sizes, picking the split
+// with the highest match quality. Lobbies of up to ExactLimit players are
+// searched exhaustively, larger ones start from a snake draft by ordinal and
+// are improved by swapping players between teams until no swap helps.
+// Weight, Rank and Score in options are ignored.
+func Balance(players []types.Rating, teamSizes []int, options *types.OpenSkillOptions) (Partition, error) {
+	total := 0
+	for i, size := range teamSizes {
+		if size <= 0 {
+			return Partition{}, fmt.Errorf("%w: team %d has size %d", ErrInvalidTeamSizes, i, size)
+		}
+		total += size
+	}
+
+	if len(teamSizes) == 0 || total != len(players) {
+		return Partition{}, fmt.Errorf("%w: %d places for %d players", ErrInvalidTeamSizes, total, len(players))
+	}
+
+	if err := rating.Validate([]types.Team{players}, nil); err != nil {
+		return Partition{}, err
+	}
+
+	b := &balancer{
+		players:   players,
+		teamSizes: teamSizes,
+		options:   qualityOptions(options),
+	}
+
+	var partition Partition
+	if len(players) <= ExactLimit {
+		partition = b.exact()
+	} else {
+		partition = b.localSearch()
+	}
+
+	for _, team := range partition.Teams {
+		sort.Ints(team)
+	}
+
+	return partition, nil
+}
+
+// qualityOptions returns a copy of options without anything that depends on
+// the shape of the teams
+func qualityOptions(options *types.OpenSkillOptions) *types.OpenSkillOptions {
+	if options == nil {
+		return nil
+	}
+
+	o := *options
+	o.Weight = nil
+	o.Rank = nil
+	o.Score = nil
+	o.Scores = nil
+	return &o
+}
+
+// balancer holds the state of a call to Balance
+type balancer struct {
+	players   []types.Rating
+	teamSizes []int
+	options   *types.OpenSkillOptions
+}
+
+// quality returns the match quality of teams of player indexes
+func (b *balancer) quality(teams [][]int) float64 {
+	return rating.MatchQuality(Partition{Teams: teams}.Ratings(b.players), b.options)
+}
+
+// exact tries every split, skipping the ones that only swap two teams of the
+// same size
+func (b *balancer) exact() Partition {
+	teams := make([][]int, len(b.teamSizes))
+	best := Partition{Quality: -1}
+
+	var assign func(player int)
+	assign = func(player int) {
+		if player == len(b.players) {
+			if q := b.quality(teams); q > best.Quality {
+				best = Partition{Teams: copyTeams(teams), Quality: q}
+			}
+			return
+		}
+
+		for t := range teams {
+			if len(teams[t]) == b.teamSizes[t] {
+				continue
+			}
+
+			// Empty teams of the same size are interchangeable, so the player
+			// only goes into the first of them
+			if len(teams[t]) == 0 && firstEmpty(teams, b.teamSizes, t) != t {
+				continue
+			}
+
+			teams[t] = append(teams[t], player)
+			assign(player + 1)
+			teams[t] = teams[t][:len(teams[t])-1]
+		}
+	}
+	assign(0)
+
+	return best
+}
+
+// firstEmpty returns the first empty team with the same size as team t
+func firstEmpty(teams [][]int, teamSizes []int, t int) int {
+	for i := range teams {
+		if len(teams[i]) == 0 && teamSizes[i] == teamSizes[t] {
+			return i
+		}
+	}
+	return t
+}
+
+// localSearch drafts the teams by ordinal, then swaps players between teams
+// as long as a swap improves the quality of the match
+func (b *balancer) localSearch() Partition {
+	order := make([]int, len(b.players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rating.Ordinal(b.players[order[i]]) > rating.Ordinal(b.players[order[j]])
+	})
+
+	// Snake draft: teams pick in turn, reversing the order at every round,
+	// until they are full
+	teams := make([][]int, len(b.teamSizes))
+	for round := 0; len(order) > 0; round++ {
+		for k := range teams {
+			t := k
+			if round%2 == 1 {
+				t = len(teams) - 1 - k
+			}
+			if len(teams[t]) < b.teamSizes[t] && len(order) > 0 {
+				teams[t] = append(teams[t], order[0])
+				order = order[1:]
+			}
+		}
+	}
+
+	best := b.quality(teams)
+	for improved := true; improved; {
+		improved = false
+		for t := range teams {
+			for u := t + 1; u < len(teams); u++ {
+				for i := range teams[t] {
+					for j := range teams[u] {
+						teams[t][i], teams[u][j] = teams[u][j], teams[t][i]
+						if q := b.quality(teams); q > best {
+							best = q
+							improved = true
+							continue
+						}
+						teams[t][i], teams[u][j] = teams[u][j], teams[t][i]
+					}
+				}
+			}
+		}
+	}
+
+	return Partition{Teams: teams, Quality: best}
+}
+
+func copyTeams(src [][]int) [][]int {
+	dest := make([][]int, len(src))
+	for i, team := range src {
+		dest[i] = append([]int(nil), team...)
+	}
+	return dest
+}
@@ -0,0 +1,148 @@
+package matchmaking_test
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/matchmaking"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// lobby returns n players with random ratings, always the same for a seed
+func lobby(n int, seed uint64) []types.Rating {
+	r := rand.New(rand.NewPCG(seed, seed))
+	players := make([]types.Rating, n)
+	for i := range players {
+		players[i] = rating.NewWithOptions(&types.OpenSkillOptions{
+			Mu:    ptr.Float64(10 + 30*r.Float64()),
+			Sigma: ptr.Float64(1 + 7*r.Float64()),
+		})
+	}
+	return players
+}
+
+// assertPartition checks that every player is in exactly one team of the
+// right size
+func assertPartition(is *_is.I, partition matchmaking.Partition, players int, teamSizes []int) {
+	is.Helper()
+	is.Equal(len(partition.Teams), len(teamSizes))
+	seen := make(map[int]bool)
+	for t, team := range partition.Teams {
+		is.Equal(len(team), teamSizes[t])
+		for _, index := range team {
+			is.True(!seen[index])
+			seen[index] = true
+		}
+	}
+	is.Equal(len(seen), players)
+}
+
+func TestBalanceSplitsStrongPlayers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	strong := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(40)})
+	weak := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(10)})
+	players := []types.Rating{strong, strong, weak, weak}
+
+	partition, err := matchmaking.Balance(players, []int{2, 2}, nil)
+	is.NoErr(err)
+	is.Equal(partition.Teams, [][]int{{0, 2}, {1, 3}})
+	is.Equal(partition.Quality, rating.MatchQuality(partition.Ratings(players), nil))
+}
+
+func TestBalanceFindsTheBestSplit(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := lobby(8, 1)
+	partition, err := matchmaking.Balance(players, []int{4, 4}, nil)
+	is.NoErr(err)
+	assertPartition(is, partition, 8, []int{4, 4})
+
+	// Check every split by hand
+	best := 0.0
+	for mask := 0; mask < 1<<8; mask++ {
+		var a, b types.Team
+		for i, player := range players {
+			if mask&(1<<i) != 0 {
+				a = append(a, player)
+			} else {
+				b = append(b, player)
+			}
+		}
+		if len(a) == 4 {
+			if q := rating.MatchQuality([]types.Team{a, b}, nil); q > best {
+				best = q
+			}
+		}
+	}
+	is.Equal(partition.Quality, best)
+}
+
+func TestBalanceUnevenTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := lobby(7, 2)
+	partition, err := matchmaking.Balance(players, []int{1, 2, 4}, nil)
+	is.NoErr(err)
+	assertPartition(is, partition, 7, []int{1, 2, 4})
+}
+
+func TestBalanceLargeLobbies(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := lobby(40, 3)
+	teamSizes := []int{10, 10, 10, 10}
+	partition, err := matchmaking.Balance(players, teamSizes, nil)
+	is.NoErr(err)
+	assertPartition(is, partition, 40, teamSizes)
+
+	// The local search is deterministic, and beats teams made in lobby order
+	again, err := matchmaking.Balance(players, teamSizes, nil)
+	is.NoErr(err)
+	is.Equal(partition, again)
+
+	naive := []types.Team{players[0:10], players[10:20], players[20:30], players[30:40]}
+	is.True(partition.Quality > rating.MatchQuality(naive, nil))
+}
+
+func TestBalanceIgnoresMatchOptions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := lobby(4, 4)
+	partition, err := matchmaking.Balance(players, []int{2, 2}, &types.OpenSkillOptions{
+		Weight: [][]float64{{1}},
+		Rank:   []int{1, 2, 3},
+	})
+	is.NoErr(err)
+	assertPartition(is, partition, 4, []int{2, 2})
+}
+
+func TestBalanceErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := lobby(4, 5)
+
+	_, err := matchmaking.Balance(players, []int{2, 3}, nil)
+	is.True(errors.Is(err, matchmaking.ErrInvalidTeamSizes))
+
+	_, err = matchmaking.Balance(players, []int{4, 0}, nil)
+	is.True(errors.Is(err, matchmaking.ErrInvalidTeamSizes))
+
+	_, err = matchmaking.Balance(nil, nil, nil)
+	is.True(errors.Is(err, matchmaking.ErrInvalidTeamSizes))
+
+	players[2].Sigma = 0
+	_, err = matchmaking.Balance(players, []int{2, 2}, nil)
+	is.True(errors.Is(err, rating.ErrInvalidSigma))
+}
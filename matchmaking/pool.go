@@ -0,0 +1,89 @@
+package matchmaking
+
+import (
+	"sort"
+
+	"github.com/intinig/go-openskill/rating"
+)
+
+// entry is a waiting player together with their ordinal
+type entry struct {
+	Ticket
+	ordinal float64
+}
+
+// pool holds the players of a Tick sorted by ordinal, then by ID, so that a
+// player and the players within their window are found with a binary search.
+// Matched players stay where they are, up and down lead past them: following
+// up from i gives the first unmatched player at or after i, following down
+// from i + 1 gives one more than the last unmatched player at or before i.
+// Both are shortened as they are followed, so skipping matched players costs
+// next to nothing.
+type pool struct {
+	entries []entry
+	up      []int
+	down    []int
+}
+
+// newPool returns a pool of tickets, none of them matched
+func newPool(tickets []Ticket) *pool {
+	p := &pool{
+		entries: make([]entry, len(tickets)),
+		up:      make([]int, len(tickets)+1),
+		down:    make([]int, len(tickets)+1),
+	}
+	for i, t := range tickets {
+		p.entries[i] = entry{Ticket: t, ordinal: rating.Ordinal(t.Rating)}
+	}
+	sort.Slice(p.entries, func(i, j int) bool {
+		return p.entries[i].before(p.entries[j].ordinal, p.entries[j].ID)
+	})
+	for i := range p.up {
+		p.up[i] = i
+		p.down[i] = i
+	}
+	return p
+}
+
+// before reports whether e comes before a player with ordinal and id
+func (e entry) before(ordinal float64, id string) bool {
+	if e.ordinal != ordinal {
+		return e.ordinal < ordinal
+	}
+	return e.ID < id
+}
+
+// find returns the index of t
+func (p *pool) find(t Ticket) int {
+	ordinal := rating.Ordinal(t.Rating)
+	return sort.Search(len(p.entries), func(i int) bool {
+		return !p.entries[i].before(ordinal, t.ID)
+	})
+}
+
+// match marks the player at index i as matched
+func (p *pool) match(i int) {
+	p.up[i] = i + 1
+	p.down[i+1] = i
+}
+
+// next returns the index of the first unmatched player at or after i, or the
+// number of players when there is none
+func (p *pool) next(i int) int {
+	for p.up[i] != i {
+		p.up[i] = p.up[p.up[i]]
+		i = p.up[i]
+	}
+	return i
+}
+
+// previous returns the index of the last unmatched player at or before i, or
+// -1 when there is none
+func (p *pool) previous(i int) int {
+	i++
+	for p.down[i] != i {
+		p.down[i] = p.down[p.down[i]]
+		i = p.down[i]
+	}
+	return i - 1
+}
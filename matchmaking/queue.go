@@ -0,0 +1,445 @@
+package matchmaking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+const (
+	// DefaultOrdinalWindow is the ordinal window of a player who just joined
+	// the queue, when QueueOptions does not set one
+	DefaultOrdinalWindow = 5.0
+	// DefaultSigmaWindow is the sigma window of a player who just joined the
+	// queue, when QueueOptions does not set one
+	DefaultSigmaWindow = 2.0
+	// DefaultWidenEvery is how often windows widen, when QueueOptions does not
+	// set it
+	DefaultWidenEvery = 30 * time.Second
+)
+
+// ErrAlreadyQueued is returned when a player who is already waiting joins the
+// queue again
+var ErrAlreadyQueued = errors.New("openskill: player already queued")
+
+// Clock tells the time to a Queue
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock of the system
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// ManualClock is a Clock that only moves when told to, so that a Queue can be
+// driven deterministically, for instance in tests
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a new ManualClock set to start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Ticket is a player waiting in a Queue
+type Ticket struct {
+	// ID identifies the player
+	ID string
+	// Rating is the rating of the player
+	Rating types.Rating
+	// Since is when the player started waiting, a player can bring the time
+	// they already waited somewhere else. The clock of the queue is used when
+	// it is zero.
+	Since time.Time
+}
+
+// QueueOptions configures a Queue
+type QueueOptions struct {
+	// TeamSizes is the shape of the matches, for instance {5, 5}. It must have
+	// at least two teams.
+	TeamSizes []int
+	// OrdinalWindow is the largest ordinal difference a player who just
+	// joined accepts between themselves and the other players of a match. The
+	// default value is DefaultOrdinalWindow.
+	OrdinalWindow float64
+	// SigmaWindow is the largest sigma difference a player who just joined
+	// accepts between themselves and the other players of a match. The
+	// default value is DefaultSigmaWindow.
+	SigmaWindow float64
+	// WidenEvery is how often both windows grow by their initial size while a
+	// player waits. The default value is DefaultWidenEvery.
+	WidenEvery time.Duration
+	// MinDrawProbability is the lowest draw probability, see
+	// rating.PredictDraw, of a match the queue forms. The default value is 0.
+	MinDrawProbability float64
+	// Clock tells the time, the default value is SystemClock
+	Clock Clock
+	// Options are the options used to balance and predict matches
+	Options *types.OpenSkillOptions
+}
+
+// Match is a match formed by a Queue
+type Match struct {
+	// Teams holds the players of each team, in the shape of
+	// QueueOptions.TeamSizes
+	Teams [][]Ticket
+	// WinProbabilities is the probability of each team winning, see
+	// rating.PredictWin
+	WinProbabilities []float64
+	// DrawProbability is the probability of the match ending in a draw, see
+	// rating.PredictDraw
+	DrawProbability float64
+}
+
+// Ratings returns the teams of the match, ready for rating.Rate
+func (m Match) Ratings() []types.Team {
+	teams := make([]types.Team, len(m.Teams))
+	for i, team := range m.Teams {
+		teams[i] = make(types.Team, len(team))
+		for j, ticket := range team {
+			teams[i][j] = ticket.Rating
+		}
+	}
+	return teams
+}
+
+// Queue holds players waiting for a match and forms matches out of them when
+// Tick is called. Players who have waited longer are matched first, and
+// accept opponents that are further away from them. It is safe for concurrent
+// use.
+type Queue struct {
+	mu      sync.Mutex
+	options QueueOptions
+	size    int
+	tickets []Ticket
+	queued  map[string]bool
+}
+
+// NewQueue returns a new empty Queue
+func NewQueue(options *QueueOptions) (*Queue, error) {
+	if options == nil {
+		options = &QueueOptions{}
+	}
+
+	o := *options
+	o.TeamSizes = append([]int(nil), options.TeamSizes...)
+	if o.OrdinalWindow <= 0 {
+		o.OrdinalWindow = DefaultOrdinalWindow
+	}
+	if o.SigmaWindow <= 0 {
+		o.SigmaWindow = DefaultSigmaWindow
+	}
+	if o.WidenEvery <= 0 {
+		o.WidenEvery = DefaultWidenEvery
+	}
+	if o.Clock == nil {
+		o.Clock = SystemClock
+	}
+	o.Options = qualityOptions(options.Options)
+
+	if len(o.TeamSizes) < 2 {
+		return nil, fmt.Errorf("%w: a match needs at least two teams", ErrInvalidTeamSizes)
+	}
+
+	size := 0
+	for i, teamSize := range o.TeamSizes {
+		if teamSize <= 0 {
+			return nil, fmt.Errorf("%w: team %d has size %d", ErrInvalidTeamSizes, i, teamSize)
+		}
+		size += teamSize
+	}
+
+	return &Queue{
+		options: o,
+		size:    size,
+		queued:  make(map[string]bool),
+	}, nil
+}
+
+// Enqueue adds a player to the queue
+func (q *Queue) Enqueue(ticket Ticket) error {
+	if err := rating.Validate([]types.Team{{ticket.Rating}}, nil); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.queued[ticket.ID] {
+		return fmt.Errorf("%w: %s", ErrAlreadyQueued, ticket.ID)
+	}
+
+	if ticket.Since.IsZero() {
+		ticket.Since = q.options.Clock.Now()
+	}
+	q.tickets = append(q.tickets, ticket)
+	q.queued[ticket.ID] = true
+
+	return nil
+}
+
+// Dequeue removes a player from the queue and reports whether they were in it
+func (q *Queue) Dequeue(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.queued[id] {
+		return false
+	}
+
+	for i, t := range q.tickets {
+		if t.ID == id {
+			q.tickets = append(q.tickets[:i], q.tickets[i+1:]...)
+			break
+		}
+	}
+	delete(q.queued, id)
+
+	return true
+}
+
+// Len returns the number of players waiting
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.tickets)
+}
+
+// Tick forms as many matches as it can out of the players waiting, removes
+// their players from the queue and returns them
+func (q *Queue) Tick() []Match {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.options.Clock.Now()
+
+	// Players who have waited the longest get to pick first
+	waiting := append([]Ticket(nil), q.tickets...)
+	sort.SliceStable(waiting, func(i, j int) bool {
+		return waiting[i].Since.Before(waiting[j].Since)
+	})
+
+	pool := newPool(waiting)
+
+	var matches []Match
+	matched := make(map[string]bool)
+	for _, anchor := range waiting {
+		if matched[anchor.ID] {
+			continue
+		}
+
+		match, ok := q.bestMatch(anchor, pool, now)
+		if !ok {
+			continue
+		}
+
+		for _, team := range match.Teams {
+			for _, t := range team {
+				matched[t.ID] = true
+				pool.match(pool.find(t))
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	remaining := q.tickets[:0]
+	for _, t := range q.tickets {
+		if matched[t.ID] {
+			delete(q.queued, t.ID)
+		} else {
+			remaining = append(remaining, t)
+		}
+	}
+	q.tickets = remaining
+
+	return matches
+}
+
+// Run calls Tick every interval until ctx is done, handing the matches to
+// matched whenever there are some
+func (q *Queue) Run(ctx context.Context, interval time.Duration, matched func([]Match)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if matches := q.Tick(); len(matches) > 0 {
+				matched(matches)
+			}
+		}
+	}
+}
+
+// windows returns the ordinal and sigma windows of a ticket at now
+func (q *Queue) windows(t Ticket, now time.Time) (float64, float64) {
+	waited := math.Max(float64(now.Sub(t.Since)), 0)
+	scale := 1 + math.Floor(waited/float64(q.options.WidenEvery))
+	return q.options.OrdinalWindow * scale, q.options.SigmaWindow * scale
+}
+
+// accepts reports whether a and b are within each other's windows
+func (q *Queue) accepts(a, b Ticket, now time.Time) bool {
+	ordinalA, ordinalB := rating.Ordinal(a.Rating), rating.Ordinal(b.Rating)
+	sigma := math.Abs(a.Rating.Sigma - b.Rating.Sigma)
+
+	for _, t := range []Ticket{a, b} {
+		ordinalWindow, sigmaWindow := q.windows(t, now)
+		if math.Abs(ordinalA-ordinalB) > ordinalWindow || sigma > sigmaWindow {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bestMatch returns the best match anchor can play with the unmatched players
+// of pool. Candidates are runs of players next to each other by ordinal that
+// include the anchor, every player of a run must accept every other one.
+func (q *Queue) bestMatch(anchor Ticket, pool *pool, now time.Time) (Match, bool) {
+	ordinal := rating.Ordinal(anchor.Rating)
+	position := pool.find(anchor)
+
+	// A run that includes the anchor holds at most size - 1 players on each
+	// side of it, so only the closest players it accepts are needed, and
+	// nobody outside of its ordinal window can be one of them
+	ordinalWindow, _ := q.windows(anchor, now)
+	var below, above []Ticket
+	for i := pool.previous(position - 1); i >= 0 && len(below) < q.size-1; i = pool.previous(i - 1) {
+		e := pool.entries[i]
+		if ordinal-e.ordinal > ordinalWindow {
+			break
+		}
+		if q.accepts(anchor, e.Ticket, now) {
+			below = append(below, e.Ticket)
+		}
+	}
+	for i := pool.next(position + 1); i < len(pool.entries) && len(above) < q.size-1; i = pool.next(i + 1) {
+		e := pool.entries[i]
+		if e.ordinal-ordinal > ordinalWindow {
+			break
+		}
+		if q.accepts(anchor, e.Ticket, now) {
+			above = append(above, e.Ticket)
+		}
+	}
+
+	if len(below)+len(above)+1 < q.size {
+		return Match{}, false
+	}
+
+	compatible := make([]Ticket, 0, len(below)+len(above)+1)
+	for i := len(below) - 1; i >= 0; i-- {
+		compatible = append(compatible, below[i])
+	}
+	compatible = append(compatible, anchor)
+	compatible = append(compatible, above...)
+
+	var best Match
+	found := false
+	for start := 0; start+q.size <= len(compatible); start++ {
+		group := compatible[start : start+q.size]
+
+		if !q.allAccept(group, now) {
+			continue
+		}
+
+		candidate, ok := q.score(group)
+		if ok && (!found || better(candidate, best)) {
+			best = candidate
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// allAccept reports whether every player of group accepts every other one
+func (q *Queue) allAccept(group []Ticket, now time.Time) bool {
+	for i := range group {
+		for j := i + 1; j < len(group); j++ {
+			if !q.accepts(group[i], group[j], now) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// score balances group into teams and predicts the outcome of the match, it
+// reports false when the match is less likely to draw than allowed
+func (q *Queue) score(group []Ticket) (Match, bool) {
+	players := make([]types.Rating, len(group))
+	for i, t := range group {
+		players[i] = t.Rating
+	}
+
+	partition, err := Balance(players, q.options.TeamSizes, q.options.Options)
+	if err != nil {
+		return Match{}, false
+	}
+
+	match := Match{
+		Teams: make([][]Ticket, len(partition.Teams)),
+	}
+	for i, team := range partition.Teams {
+		match.Teams[i] = make([]Ticket, len(team))
+		for j, index := range team {
+			match.Teams[i][j] = group[index]
+		}
+	}
+
+	teams := match.Ratings()
+	match.WinProbabilities = rating.PredictWin(teams, q.options.Options)
+	match.DrawProbability = rating.PredictDraw(teams, q.options.Options)
+
+	return match, match.DrawProbability >= q.options.MinDrawProbability
+}
+
+// better reports whether match a is better than match b: more likely to
+// draw, or as likely to draw but with closer odds of winning
+func better(a, b Match) bool {
+	if a.DrawProbability != b.DrawProbability {
+		return a.DrawProbability > b.DrawProbability
+	}
+	return spread(a.WinProbabilities) < spread(b.WinProbabilities)
+}
+
+// spread returns the difference between the largest and smallest values
+func spread(values []float64) float64 {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lowest = math.Min(lowest, v)
+		highest = math.Max(highest, v)
+	}
+	return highest - lowest
+}
@@ -0,0 +1,324 @@
+package matchmaking_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/matchmaking"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// player returns a ticket for a player with the given ordinal and sigma
+func player(id string, ordinal, sigma float64) matchmaking.Ticket {
+	return matchmaking.Ticket{
+		ID:     id,
+		Rating: types.Rating{Mu: ordinal + 3*sigma, Sigma: sigma, Z: 3},
+	}
+}
+
+// newQueue returns a queue for duels driven by a manual clock
+func newQueue(t *testing.T, options matchmaking.QueueOptions) (*matchmaking.Queue, *matchmaking.ManualClock) {
+	t.Helper()
+	clock := matchmaking.NewManualClock(start)
+	options.Clock = clock
+	if options.TeamSizes == nil {
+		options.TeamSizes = []int{1, 1}
+	}
+	q, err := matchmaking.NewQueue(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q, clock
+}
+
+// ids returns the player IDs of each team of a match
+func ids(match matchmaking.Match) [][]string {
+	teams := make([][]string, len(match.Teams))
+	for i, team := range match.Teams {
+		for _, t := range team {
+			teams[i] = append(teams[i], t.ID)
+		}
+	}
+	return teams
+}
+
+func TestQueueMatchesClosePlayers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.Equal(len(q.Tick()), 0)
+
+	is.NoErr(q.Enqueue(player("b", 12, 3)))
+	matches := q.Tick()
+	is.Equal(len(matches), 1)
+	is.Equal(ids(matches[0]), [][]string{{"a"}, {"b"}})
+	is.Equal(matches[0].WinProbabilities, rating.PredictWin(matches[0].Ratings(), nil))
+	is.Equal(matches[0].DrawProbability, rating.PredictDraw(matches[0].Ratings(), nil))
+	is.Equal(q.Len(), 0)
+}
+
+func TestQueueWidensWindowsWithWaitTime(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, clock := newQueue(t, matchmaking.QueueOptions{WidenEvery: time.Minute})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.NoErr(q.Enqueue(player("b", 18, 3)))
+	is.Equal(len(q.Tick()), 0)
+
+	clock.Advance(59 * time.Second)
+	is.Equal(len(q.Tick()), 0)
+
+	// After a minute both windows are twice as large
+	clock.Advance(time.Second)
+	is.Equal(len(q.Tick()), 1)
+}
+
+func TestQueueUsesSigmaWindow(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, clock := newQueue(t, matchmaking.QueueOptions{SigmaWindow: 1, WidenEvery: time.Minute})
+
+	is.NoErr(q.Enqueue(player("a", 10, 2)))
+	is.NoErr(q.Enqueue(player("b", 10, 3.5)))
+	is.Equal(len(q.Tick()), 0)
+
+	clock.Advance(time.Minute)
+	is.Equal(len(q.Tick()), 1)
+}
+
+func TestQueueRespectsTheWindowOfNewPlayers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, clock := newQueue(t, matchmaking.QueueOptions{WidenEvery: time.Minute})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	clock.Advance(10 * time.Minute)
+
+	// a would accept b by now, but b just joined
+	is.NoErr(q.Enqueue(player("b", 18, 3)))
+	is.Equal(len(q.Tick()), 0)
+
+	clock.Advance(time.Minute)
+	is.Equal(len(q.Tick()), 1)
+}
+
+func TestQueuePicksTheMostBalancedMatch(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, clock := newQueue(t, matchmaking.QueueOptions{})
+
+	is.NoErr(q.Enqueue(player("a", 20, 3)))
+	clock.Advance(time.Second)
+	is.NoErr(q.Enqueue(player("far", 24, 3)))
+	is.NoErr(q.Enqueue(player("close", 21, 3)))
+
+	matches := q.Tick()
+	is.Equal(len(matches), 1)
+	is.Equal(ids(matches[0]), [][]string{{"a"}, {"close"}})
+	is.Equal(q.Len(), 1)
+}
+
+func TestQueueFormsBalancedTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{TeamSizes: []int{2, 2}, OrdinalWindow: 10})
+
+	for _, ticket := range []matchmaking.Ticket{
+		player("a", 18, 3),
+		player("b", 17, 3),
+		player("c", 11, 3),
+		player("d", 10, 3),
+	} {
+		is.NoErr(q.Enqueue(ticket))
+	}
+
+	matches := q.Tick()
+	is.Equal(len(matches), 1)
+	is.Equal(ids(matches[0]), [][]string{{"d", "a"}, {"c", "b"}})
+}
+
+func TestQueueMinDrawProbability(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{MinDrawProbability: 0.9})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.NoErr(q.Enqueue(player("b", 12, 3)))
+	is.Equal(len(q.Tick()), 0)
+	is.Equal(q.Len(), 2)
+}
+
+func TestQueueIsDeterministic(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	run := func() [][][]string {
+		q, clock := newQueue(t, matchmaking.QueueOptions{TeamSizes: []int{1, 1, 1}})
+		var result [][][]string
+		for i, ordinal := range []float64{10, 30, 12, 31, 14, 29, 50, 11, 33} {
+			is.NoErr(q.Enqueue(player(string(rune('a'+i)), ordinal, 2+float64(i%3))))
+			clock.Advance(20 * time.Second)
+			for _, match := range q.Tick() {
+				result = append(result, ids(match))
+			}
+		}
+		return result
+	}
+
+	first := run()
+	is.True(len(first) > 0)
+	is.Equal(first, run())
+}
+
+func TestQueueTicketsCarryWaitTime(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{WidenEvery: time.Minute})
+
+	a, b := player("a", 10, 3), player("b", 18, 3)
+	a.Since = start.Add(-time.Minute)
+	b.Since = start.Add(-time.Minute)
+	is.NoErr(q.Enqueue(a))
+	is.NoErr(q.Enqueue(b))
+	is.Equal(len(q.Tick()), 1)
+}
+
+func TestQueueEnqueueAndDequeue(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.True(errors.Is(q.Enqueue(player("a", 10, 3)), matchmaking.ErrAlreadyQueued))
+	is.True(errors.Is(q.Enqueue(player("z", 10, 0)), rating.ErrInvalidSigma))
+	is.Equal(q.Len(), 1)
+
+	is.True(q.Dequeue("a"))
+	is.True(!q.Dequeue("a"))
+	is.Equal(q.Len(), 0)
+
+	// Matched players can queue again
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.NoErr(q.Enqueue(player("b", 10, 3)))
+	is.Equal(len(q.Tick()), 1)
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.Equal(q.Len(), 1)
+}
+
+func TestNewQueueErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	_, err := matchmaking.NewQueue(nil)
+	is.True(errors.Is(err, matchmaking.ErrInvalidTeamSizes))
+
+	_, err = matchmaking.NewQueue(&matchmaking.QueueOptions{TeamSizes: []int{1, 0}})
+	is.True(errors.Is(err, matchmaking.ErrInvalidTeamSizes))
+}
+
+func TestQueueRun(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{})
+
+	is.NoErr(q.Enqueue(player("a", 10, 3)))
+	is.NoErr(q.Enqueue(player("b", 12, 3)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	found := make(chan []matchmaking.Match, 1)
+	go q.Run(ctx, time.Millisecond, func(matches []matchmaking.Match) {
+		found <- matches
+	})
+
+	select {
+	case matches := <-found:
+		is.Equal(len(matches), 1)
+	case <-ctx.Done():
+		t.Fatal("no match formed")
+	}
+}
+
+func TestQueueLargeQueue(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	q, _ := newQueue(t, matchmaking.QueueOptions{TeamSizes: []int{2, 2}})
+
+	for i, r := range lobby(400, 3) {
+		is.NoErr(q.Enqueue(matchmaking.Ticket{ID: fmt.Sprintf("p%d", i), Rating: r}))
+	}
+
+	matches := q.Tick()
+	is.True(len(matches) > 0)
+
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		var players []matchmaking.Ticket
+		for _, team := range match.Teams {
+			players = append(players, team...)
+		}
+		is.Equal(len(players), 4)
+
+		for i, a := range players {
+			is.True(!seen[a.ID]) // a player is in two matches
+			seen[a.ID] = true
+			for _, b := range players[i+1:] {
+				is.True(math.Abs(rating.Ordinal(a.Rating)-rating.Ordinal(b.Rating)) <= matchmaking.DefaultOrdinalWindow)
+				is.True(math.Abs(a.Rating.Sigma-b.Rating.Sigma) <= matchmaking.DefaultSigmaWindow)
+			}
+		}
+	}
+	is.Equal(q.Len(), 400-len(seen))
+}
+
+func BenchmarkQueueTick(b *testing.B) {
+	for _, n := range []int{100, 1000, 10000} {
+		// New accounts all share the same rating, and so the same ordinal
+		identical := make([]types.Rating, n)
+		for i := range identical {
+			identical[i] = rating.New()
+		}
+
+		for _, tt := range []struct {
+			name    string
+			players []types.Rating
+		}{
+			{"Spread", lobby(n, 1)},
+			{"Identical", identical},
+		} {
+			b.Run(fmt.Sprintf("%s/%dPlayers", tt.name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					q, err := matchmaking.NewQueue(&matchmaking.QueueOptions{
+						TeamSizes: []int{1, 1},
+						Clock:     matchmaking.NewManualClock(start),
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+					for j, r := range tt.players {
+						if err := q.Enqueue(matchmaking.Ticket{ID: fmt.Sprintf("p%d", j), Rating: r}); err != nil {
+							b.Fatal(err)
+						}
+					}
+					b.StartTimer()
+
+					q.Tick()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"math"
+
+	"github.com/intinig/go-openskill/types"
+)
+
+// bradleyTerryPair returns the omega and delta contributions of comparing the
+// team i against the team q with the logistic Bradley-Terry model
+func (m *Constants) bradleyTerryPair(options *types.OpenSkillOptions, iTeamRating, qTeamRating types.TeamRating) (float64, float64) {
+	ciq := math.Sqrt(iTeamRating.TeamSigmaSquared + qTeamRating.TeamSigmaSquared + m.TwoBetaSquared)
+	piq := 1 / (1 + math.Exp((qTeamRating.TeamMu-iTeamRating.TeamMu)/ciq))
+	sigmaSquaredToCiq := iTeamRating.TeamSigmaSquared / ciq
+	gamma := m.gamma(options, iTeamRating, ciq)
+
+	omega := sigmaSquaredToCiq * (m.U.Score(qTeamRating.Rank, iTeamRating.Rank) - piq)
+	delta := ((gamma * sigmaSquaredToCiq) / ciq) * piq * (1 - piq)
+
+	return omega, delta
+}
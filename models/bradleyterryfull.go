@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/intinig/go-openskill/types"
+)
+
+// BradleyTerryFull is a logistic model that compares every team against every
+// other team in the match
+type BradleyTerryFull struct {
+	Constants
+}
+
+// NewBradleyTerryFull returns a new BradleyTerryFull model
+func NewBradleyTerryFull(options *types.OpenSkillOptions) *BradleyTerryFull {
+	return &BradleyTerryFull{
+		Constants: NewConstants(options),
+	}
+}
+
+// Rate rates a set of teams
+func (b *BradleyTerryFull) Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
+	// Initialize options
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	returning := make([]types.Team, len(teams))
+
+	// Create a teamRatings struct for each team
+	teamRatings := b.U.TeamRating(teams, options)
+
+	// Main loop, each team is compared against all the other teams
+	for i, iTeamRating := range teamRatings {
+		omega, delta := 0.0, 0.0
+		for q, qTeamRating := range teamRatings {
+			if i == q {
+				continue
+			}
+
+			qOmega, qDelta := b.bradleyTerryPair(options, iTeamRating, qTeamRating)
+			omega += b.marginFactor(options, iTeamRating, qTeamRating) * qOmega
+			delta += qDelta
+		}
+
+		returning[i] = b.updateTeam(iTeamRating, omega, delta)
+	}
+
+	return returning
+}
@@ -0,0 +1,203 @@
+package models_test
+
+import (
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestBradleyTerryFullInitialization(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	model := models.NewBradleyTerryFull(nil)
+	is.Equal(model.Epsilon, 0.0001)
+	is.Equal(model.Kappa, 0.0001)
+	is.Equal(model.Mu, 25.0)
+	is.Equal(model.Sigma, 25.0/3.0)
+	is.Equal(model.Beta, model.Sigma/2.0)
+
+	model = models.NewBradleyTerryFull(&types.OpenSkillOptions{
+		Epsilon: ptr.Float64(0.00002),
+		Kappa:   ptr.Float64(0.001),
+		Beta:    ptr.Float64(11.11),
+	})
+	is.Equal(model.Epsilon, 0.00002)
+	is.Equal(model.Kappa, 0.001)
+	is.Equal(model.Beta, 11.11)
+}
+
+func TestBradleyTerryFullSoloGameDoesNotChangeRating(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rating.New()
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{{r}}, nil)
+	is.Equal(teams, []types.Team{{r}})
+}
+
+func TestBradleyTerryFull2PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 27.63523138347365, Sigma: 8.065506316323548, Z: 3}},
+		{types.Rating{Mu: 22.36476861652635, Sigma: 8.065506316323548, Z: 3}},
+	})
+}
+
+func TestBradleyTerryFull3PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 30.2704627669473, Sigma: 7.788474807872566, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 7.788474807872566, Z: 3}},
+		{types.Rating{Mu: 19.7295372330527, Sigma: 7.788474807872566, Z: 3}},
+	})
+}
+
+func TestBradleyTerryFull4PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 32.90569415042095, Sigma: 7.5012190693964005, Z: 3}},
+		{types.Rating{Mu: 27.63523138347365, Sigma: 7.5012190693964005, Z: 3}},
+		{types.Rating{Mu: 22.36476861652635, Sigma: 7.5012190693964005, Z: 3}},
+		{types.Rating{Mu: 17.09430584957905, Sigma: 7.5012190693964005, Z: 3}},
+	})
+}
+
+func TestBradleyTerryFull5PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 35.5409255338946, Sigma: 7.202515895247076, Z: 3}},
+		{types.Rating{Mu: 30.2704627669473, Sigma: 7.202515895247076, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 7.202515895247076, Z: 3}},
+		{types.Rating{Mu: 19.729537233052703, Sigma: 7.202515895247076, Z: 3}},
+		{types.Rating{Mu: 14.4590744661054, Sigma: 7.202515895247076, Z: 3}},
+	})
+}
+
+func TestBradleyTerryFull3TeamsWithDifferentPlayersNumbers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New(), rating.New(), rating.New()},
+		{rating.New()},
+		{rating.New(), rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{
+			types.Rating{Mu: 25.992743915179297, Sigma: 8.19709997489984, Z: 3},
+			types.Rating{Mu: 25.992743915179297, Sigma: 8.19709997489984, Z: 3},
+			types.Rating{Mu: 25.992743915179297, Sigma: 8.19709997489984, Z: 3},
+		},
+		{types.Rating{Mu: 28.48909130001799, Sigma: 8.220848339985736, Z: 3}},
+		{
+			types.Rating{Mu: 20.518164784802718, Sigma: 8.127515465304823, Z: 3},
+			types.Rating{Mu: 20.518164784802718, Sigma: 8.127515465304823, Z: 3},
+		},
+	})
+}
+
+func TestBradleyTerryFullSeries(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryFull(nil)
+
+	p00 := rating.New()
+	p10 := rating.New()
+	p20 := rating.New()
+	p30 := rating.New()
+	p40 := rating.New()
+
+	m1 := rating.Rate([]types.Team{
+		{p00}, {p10}, {p20}, {p30}, {p40},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 7, 7, 5, 5},
+	})
+
+	p01 := m1[0][0]
+	p11 := m1[1][0]
+	p21 := m1[2][0]
+	p31 := m1[3][0]
+	p41 := m1[4][0]
+
+	p02 := p01
+	p32 := p31
+
+	m2 := rating.Rate([]types.Team{
+		{p41}, {p21}, {p11},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 5, 5},
+	})
+
+	p42 := m2[0][0]
+	p22 := m2[1][0]
+	p12 := m2[2][0]
+
+	p43 := p42
+
+	m3 := rating.Rate([]types.Team{
+		{p32}, {p12}, {p22}, {p02},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 9, 7, 7},
+	})
+
+	p33 := m3[0][0]
+	p13 := m3[1][0]
+	p23 := m3[2][0]
+	p03 := m3[3][0]
+
+	is.Equal(p03.Mu, 27.643471362460662)
+	is.Equal(p03.Sigma, 6.716636757697851)
+	is.Equal(p13.Mu, 28.844979380406198)
+	is.Equal(p13.Sigma, 6.310098391579701)
+	is.Equal(p23.Mu, 20.705805440763985)
+	is.Equal(p23.Sigma, 6.310098391579701)
+	is.Equal(p33.Mu, 24.387640206683077)
+	is.Equal(p33.Sigma, 6.6687559074968545)
+	is.Equal(p43.Mu, 23.354955778428952)
+	is.Equal(p43.Sigma, 6.854096854822289)
+}
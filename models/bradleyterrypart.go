@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/intinig/go-openskill/types"
+)
+
+// BradleyTerryPart is a logistic model that only compares each team against
+// its neighbours in the ranking, which keeps it cheap for matches with a lot
+// of teams
+type BradleyTerryPart struct {
+	Constants
+}
+
+// NewBradleyTerryPart returns a new BradleyTerryPart model
+func NewBradleyTerryPart(options *types.OpenSkillOptions) *BradleyTerryPart {
+	return &BradleyTerryPart{
+		Constants: NewConstants(options),
+	}
+}
+
+// Rate rates a set of teams, teams are expected to be sorted by rank as
+// rating.Rate does
+func (b *BradleyTerryPart) Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
+	// Initialize options
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	returning := make([]types.Team, len(teams))
+
+	// Create a teamRatings struct for each team
+	teamRatings := b.U.TeamRating(teams, options)
+
+	// Each team is only compared against the teams adjacent to it
+	adjacentTeams := b.U.LadderPairs(teamRatings)
+
+	for i, iTeamRating := range teamRatings {
+		omega, delta := 0.0, 0.0
+		for _, qTeamRating := range adjacentTeams[i] {
+			qOmega, qDelta := b.bradleyTerryPair(options, iTeamRating, qTeamRating)
+			omega += b.marginFactor(options, iTeamRating, qTeamRating) * qOmega
+			delta += qDelta
+		}
+
+		returning[i] = b.updateTeam(iTeamRating, omega, delta)
+	}
+
+	return returning
+}
@@ -0,0 +1,161 @@
+package models_test
+
+import (
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestBradleyTerryPartSoloGameDoesNotChangeRating(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rating.New()
+	model := models.NewBradleyTerryPart(nil)
+	teams := model.Rate([]types.Team{{r}}, nil)
+	is.Equal(teams, []types.Team{{r}})
+}
+
+func TestBradleyTerryPart2PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryPart(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 27.63523138347365, Sigma: 8.065506316323548, Z: 3}},
+		{types.Rating{Mu: 22.36476861652635, Sigma: 8.065506316323548, Z: 3}},
+	})
+}
+
+func TestBradleyTerryPart3PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryPart(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 27.63523138347365, Sigma: 8.065506316323548, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 7.788474807872566, Z: 3}},
+		{types.Rating{Mu: 22.36476861652635, Sigma: 8.065506316323548, Z: 3}},
+	})
+}
+
+func TestBradleyTerryPart4PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryPart(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 27.63523138347365, Sigma: 8.065506316323548, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 7.788474807872566, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 7.788474807872566, Z: 3}},
+		{types.Rating{Mu: 22.36476861652635, Sigma: 8.065506316323548, Z: 3}},
+	})
+}
+
+func TestBradleyTerryPart3TeamsWithDifferentPlayersNumbers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryPart(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New(), rating.New(), rating.New()},
+		{rating.New()},
+		{rating.New(), rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{
+			types.Rating{Mu: 25.219231461891965, Sigma: 8.293401112661954, Z: 3},
+			types.Rating{Mu: 25.219231461891965, Sigma: 8.293401112661954, Z: 3},
+			types.Rating{Mu: 25.219231461891965, Sigma: 8.293401112661954, Z: 3},
+		},
+		{types.Rating{Mu: 28.48909130001799, Sigma: 8.220848339985736, Z: 3}},
+		{
+			types.Rating{Mu: 21.291677238090045, Sigma: 8.206896387427937, Z: 3},
+			types.Rating{Mu: 21.291677238090045, Sigma: 8.206896387427937, Z: 3},
+		},
+	})
+}
+
+func TestBradleyTerryPartSeries(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewBradleyTerryPart(nil)
+
+	p00 := rating.New()
+	p10 := rating.New()
+	p20 := rating.New()
+	p30 := rating.New()
+	p40 := rating.New()
+
+	m1 := rating.Rate([]types.Team{
+		{p00}, {p10}, {p20}, {p30}, {p40},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 7, 7, 5, 5},
+	})
+
+	p01 := m1[0][0]
+	p11 := m1[1][0]
+	p21 := m1[2][0]
+	p31 := m1[3][0]
+	p41 := m1[4][0]
+
+	p02 := p01
+	p32 := p31
+
+	m2 := rating.Rate([]types.Team{
+		{p41}, {p21}, {p11},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 5, 5},
+	})
+
+	p42 := m2[0][0]
+	p22 := m2[1][0]
+	p12 := m2[2][0]
+
+	p43 := p42
+
+	m3 := rating.Rate([]types.Team{
+		{p32}, {p12}, {p22}, {p02},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 9, 7, 7},
+	})
+
+	p33 := m3[0][0]
+	p13 := m3[1][0]
+	p23 := m3[2][0]
+	p03 := m3[3][0]
+
+	is.Equal(p03.Mu, 27.303389975875774)
+	is.Equal(p03.Sigma, 7.786799495058561)
+	is.Equal(p13.Mu, 25.349369733132217)
+	is.Equal(p13.Sigma, 7.097135631578625)
+	is.Equal(p23.Mu, 22.38855710201393)
+	is.Equal(p23.Sigma, 6.923593200487554)
+	is.Equal(p33.Mu, 22.41494662385292)
+	is.Equal(p33.Sigma, 7.540451289387335)
+	is.Equal(p43.Mu, 27.834104351799592)
+	is.Equal(p43.Sigma, 7.803747070361465)
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"math"
+
+	"github.com/intinig/go-openskill/defaults"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/types"
+	"github.com/intinig/go-openskill/util"
+)
+
+// Constants holds the hyperparameters shared by all the rating models
+type Constants struct {
+	Epsilon        float64
+	Kappa          float64
+	DrawMargin     float64
+	Beta           float64
+	BetaSquared    float64
+	TwoBetaSquared float64
+	Mu             float64
+	Sigma          float64
+	Z              int
+	U              *util.Util
+}
+
+// NewConstants resolves the model hyperparameters from options, falling back
+// to the defaults for anything that is not set
+func NewConstants(options *types.OpenSkillOptions) Constants {
+	v := defaults.Resolve(options)
+
+	bSquared := v.Beta * v.Beta
+	u := util.NewWithOptions(&util.Options{
+		BetaSquared: ptr.Float64(bSquared),
+	})
+
+	return Constants{
+		Epsilon:        v.Epsilon,
+		Kappa:          v.Kappa,
+		DrawMargin:     v.DrawMargin,
+		Z:              v.Z,
+		Mu:             v.Mu,
+		Sigma:          v.Sigma,
+		Beta:           v.Beta,
+		BetaSquared:    bSquared,
+		TwoBetaSquared: 2 * bSquared,
+		U:              u,
+	}
+}
+
+// gamma returns the dynamic factor for a team, using options.Gamma when it is
+// provided and sqrt(teamSigmaSquared) / c otherwise
+func (m *Constants) gamma(options *types.OpenSkillOptions, teamRating types.TeamRating, c float64) float64 {
+	if options.Gamma != nil {
+		return options.Gamma(teamRating)
+	}
+
+	return math.Sqrt(teamRating.TeamSigmaSquared) / c
+}
+
+// marginMode reports whether the margin-of-victory mode is on
+func (m *Constants) marginMode(options *types.OpenSkillOptions) bool {
+	return options.Margin != nil && (options.Score != nil || options.Scores != nil)
+}
+
+// marginFactor returns how much the mu update of team i against team q is
+// scaled, it is always 1 unless the margin-of-victory mode is on
+func (m *Constants) marginFactor(options *types.OpenSkillOptions, iTeamRating, qTeamRating types.TeamRating) float64 {
+	if !m.marginMode(options) {
+		return 1.0
+	}
+
+	return m.U.MarginFactor(iTeamRating.Score, qTeamRating.Score, *options.Margin)
+}
+
+// updateTeam applies the omega and delta adjustments of a team to each of its
+// players, proportionally to the share of the team variance they carry and to
+// their weight
+func (m *Constants) updateTeam(teamRating types.TeamRating, omega, delta float64) types.Team {
+	returning := make(types.Team, len(teamRating.Team))
+
+	for j, rating := range teamRating.Team {
+		weight := 1.0
+		if teamRating.Weight != nil {
+			weight = teamRating.Weight[j]
+		}
+
+		sigmaSquaredRatio := (rating.Sigma * rating.Sigma) / teamRating.TeamSigmaSquared
+		returning[j] = types.Rating{
+			Mu:    rating.Mu + weight*sigmaSquaredRatio*omega,
+			Sigma: rating.Sigma * math.Sqrt(math.Max(1-weight*weight*sigmaSquaredRatio*delta, m.Kappa)),
+			Z:     rating.Z,
+		}
+	}
+
+	return returning
+}
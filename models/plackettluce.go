@@ -3,10 +3,7 @@ package models
 import (
 	"math"
 
-	"github.com/intinig/go-openskill/ptr"
-
 	"github.com/intinig/go-openskill/types"
-	"github.com/intinig/go-openskill/util"
 )
 
 type PlackettLuceOptions struct {
@@ -18,61 +15,13 @@ type PlackettLuceOptions struct {
 }
 
 type PlackettLuce struct {
-	Epsilon        float64
-	Beta           float64
-	BetaSquared    float64
-	TwoBetaSquared float64
-	Mu             float64
-	Sigma          float64
-	Z              int
-	U              *util.Util
+	Constants
 }
 
 // NewPlackettLuce returns a new PlackettLuce model
 func NewPlackettLuce(options *types.OpenSkillOptions) *PlackettLuce {
-	if options == nil {
-		options = &types.OpenSkillOptions{}
-	}
-
-	epsilon := options.Epsilon
-	if epsilon == nil {
-		epsilon = ptr.Float64(0.0001)
-	}
-
-	z := options.Z
-	if z == nil {
-		z = ptr.Int(3)
-	}
-
-	mu := options.Mu
-	if mu == nil {
-		mu = ptr.Float64(25.0)
-	}
-
-	sigma := options.Sigma
-	if sigma == nil {
-		sigma = ptr.Float64(*mu / float64(*z))
-	}
-
-	beta := options.Beta
-	if beta == nil {
-		beta = ptr.Float64(*sigma / 2.0)
-	}
-
-	bSquared := *beta * *beta
-	u := util.NewWithOptions(&util.Options{
-		BetaSquared: ptr.Float64(bSquared),
-	})
-
 	return &PlackettLuce{
-		Epsilon:        *epsilon,
-		Z:              *z,
-		Mu:             *mu,
-		Sigma:          *sigma,
-		Beta:           *beta,
-		BetaSquared:    bSquared,
-		TwoBetaSquared: 2 * (*beta * *beta),
-		U:              u,
+		Constants: NewConstants(options),
 	}
 }
 
@@ -95,63 +44,87 @@ func (p *PlackettLuce) Rate(teams []types.Team, options *types.OpenSkillOptions)
 	// Draw probability (?)
 	c := p.U.C(teamRatings)
 
-	// sumQ
-	sumQ := p.U.SumQ(teamRatings, c)
+	// sumQ, kept in log-space so that large mu values do not overflow
+	logSumQ := p.U.LogSumQ(teamRatings, c)
 
 	// Draws per team
 	a := p.U.A(teamRatings)
 
+	// Each team is compared with all the teams ranked at or above it. Both
+	// omega and delta only depend on sums over those teams of 1 / (a * sumQ)
+	// and 1 / (a * sumQ^2), so we accumulate them once, rank by rank, instead
+	// of scanning all the teams for every team.
+	logSum1 := make([]float64, len(teamRatings))
+	logSum2 := make([]float64, len(teamRatings))
+	running1, running2 := math.Inf(-1), math.Inf(-1)
+	for _, group := range p.U.RankGroups(teamRatings) {
+		for _, q := range group {
+			logA := math.Log(float64(a[q]))
+			running1 = p.U.LogAddExp(running1, -logSumQ[q]-logA)
+			running2 = p.U.LogAddExp(running2, -2*logSumQ[q]-logA)
+		}
+		for _, q := range group {
+			logSum1[q] = running1
+			logSum2[q] = running2
+		}
+	}
+
+	// In margin-of-victory mode each team is scaled by its margins against the
+	// teams finishing right before and after it
+	margins := p.neighbourMargins(options, teamRatings)
+
 	// Main loop, we iterate across all teamRatings
 	for i, teamRating := range teamRatings {
+		iMuOverC := teamRating.TeamMu / c
 
-		iMuOverCe := math.Exp(teamRating.TeamMu / c)
+		// sum of exp(iMu/c) / sumQ[q] and of its square over the teams q
+		// ranked at or above i, each term is at most 1 so they cannot overflow
+		quotients := math.Exp(iMuOverC + logSum1[i])
+		squaredQuotients := math.Exp(2*iMuOverC + logSum2[i])
 
-		var lowerRanks []types.TeamRating
-		for _, teamRating2 := range teamRatings {
-			if teamRating2.Rank <= teamRating.Rank {
-				lowerRanks = append(lowerRanks, teamRating2)
-			}
-		}
+		omega := 1/float64(a[i]) - quotients
+		delta := math.Max(quotients-squaredQuotients, 0)
 
-		omega, delta := 0.0, 0.0
-		for q := range lowerRanks {
-			quotient := iMuOverCe / sumQ[q]
-			if i == q {
-				omega += (1.0 - quotient) / float64(a[q])
-			} else {
-				omega -= quotient / float64(a[q])
-			}
-			delta += (quotient * (1 - quotient)) / float64(a[q])
-		}
+		iGamma := p.gamma(options, teamRating, c)
+		iOmega := margins[i] * omega * (teamRating.TeamSigmaSquared / c)
+		iDelta := iGamma * delta * (teamRating.TeamSigmaSquared / (c * c))
 
-		gamma := options.Gamma
-		if gamma == nil {
-			gamma = func(tr types.TeamRating) float64 {
-				return math.Sqrt(tr.TeamSigmaSquared) / c
-			}
-		}
+		returning[i] = p.updateTeam(teamRating, iOmega, iDelta)
+	}
 
-		iGamma := gamma(teamRating)
-		iOmega := omega * (teamRating.TeamSigmaSquared / c)
-		iDelta := iGamma * delta * (teamRating.TeamSigmaSquared / (c * c))
+	return returning
+}
 
-		returningTeam := make(types.Team, len(teamRating.Team))
-
-		for j, rating := range teamRating.Team {
-			returningTeam[j] = types.Rating{
-				Mu: rating.Mu + (rating.Sigma*rating.Sigma/teamRating.TeamSigmaSquared)*iOmega,
-				Sigma: rating.Sigma * math.Sqrt(
-					math.Max(
-						1-((rating.Sigma*rating.Sigma)/teamRating.TeamSigmaSquared)*iDelta,
-						p.Epsilon,
-					),
-				),
-				Z: rating.Z,
-			}
-		}
+// neighbourMargins returns the margin factor of each team, averaged over the
+// teams finishing right before and after it. It is 1 for every team unless the
+// margin-of-victory mode is on.
+func (p *PlackettLuce) neighbourMargins(options *types.OpenSkillOptions, teamRatings []types.TeamRating) []float64 {
+	margins := make([]float64, len(teamRatings))
+	for i := range margins {
+		margins[i] = 1.0
+	}
 
-		returning[i] = returningTeam
+	if !p.marginMode(options) || len(teamRatings) < 2 {
+		return margins
 	}
 
-	return returning
+	var order []int
+	for _, group := range p.U.RankGroups(teamRatings) {
+		order = append(order, group...)
+	}
+
+	for k, i := range order {
+		total, count := 0.0, 0
+		if k > 0 {
+			total += p.marginFactor(options, teamRatings[i], teamRatings[order[k-1]])
+			count++
+		}
+		if k < len(order)-1 {
+			total += p.marginFactor(options, teamRatings[i], teamRatings[order[k+1]])
+			count++
+		}
+		margins[i] = total / float64(count)
+	}
+
+	return margins
 }
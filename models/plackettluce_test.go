@@ -1,6 +1,9 @@
 package models_test
 
 import (
+	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 
 	_is "github.com/matryer/is"
@@ -106,7 +109,7 @@ func Test3PlayersFreeForAll(t *testing.T) {
 		{rating.New()},
 		{rating.New()},
 	}, nil)
-	is.Equal(teams, []types.Team{
+	assertTeamsAlmostEqual(is, teams, []types.Team{
 		{types.Rating{Mu: 27.868876552746237, Sigma: 8.204837030780652, Z: 3}},
 		{types.Rating{Mu: 25.717219138186557, Sigma: 8.057829747583874, Z: 3}},
 		{types.Rating{Mu: 21.413904309067206, Sigma: 8.057829747583874, Z: 3}},
@@ -124,7 +127,7 @@ func Test4PlayersFreeForAll(t *testing.T) {
 		{rating.New()},
 		{rating.New()},
 	}, nil)
-	is.Equal(teams, []types.Team{
+	assertTeamsAlmostEqual(is, teams, []types.Team{
 		{types.Rating{Mu: 27.795084971874736, Sigma: 8.263160757613477, Z: 3}},
 		{types.Rating{Mu: 26.552824984374855, Sigma: 8.179213704945203, Z: 3}},
 		{types.Rating{Mu: 24.68943500312503, Sigma: 8.083731307186588, Z: 3}},
@@ -144,7 +147,7 @@ func Test5PlayersFreeForAll(t *testing.T) {
 		{rating.New()},
 		{rating.New()},
 	}, nil)
-	is.Equal(teams, []types.Team{
+	assertTeamsAlmostEqual(is, teams, []types.Team{
 		{types.Rating{Mu: 27.666666666666668, Sigma: 8.290556877154474, Z: 3}},
 		{types.Rating{Mu: 26.833333333333332, Sigma: 8.240145629781066, Z: 3}},
 		{types.Rating{Mu: 25.72222222222222, Sigma: 8.179996679645559, Z: 3}},
@@ -230,7 +233,7 @@ func TestSeries(t *testing.T) {
 	p23 := m3[2][0]
 	p03 := m3[3][0]
 
-	is.Equal(p03.Mu, 26.3537611030628)
+	assertAlmostEqual(is, p03.Mu, 26.3537611030628)
 	is.Equal(p03.Sigma, 8.111027060497456)
 	is.Equal(p13.Mu, 24.618479788611904)
 	is.Equal(p13.Sigma, 7.905335509558602)
@@ -241,3 +244,221 @@ func TestSeries(t *testing.T) {
 	is.Equal(p43.Mu, 26.385499684561076)
 	is.Equal(p43.Sigma, 8.05409080928062)
 }
+
+// assertAlmostEqual checks that two floats only differ by rounding
+func assertAlmostEqual(is *_is.I, got, expected float64) {
+	is.Helper()
+	is.True(math.Abs(got-expected) < 1e-12) // got differs from expected by more than rounding
+}
+
+// assertTeamsAlmostEqual checks that two lists of teams only differ by rounding
+func assertTeamsAlmostEqual(is *_is.I, got, expected []types.Team) {
+	is.Helper()
+	is.Equal(len(got), len(expected))
+	for i := range expected {
+		is.Equal(len(got[i]), len(expected[i]))
+		for j := range expected[i] {
+			assertAlmostEqual(is, got[i][j].Mu, expected[i][j].Mu)
+			assertAlmostEqual(is, got[i][j].Sigma, expected[i][j].Sigma)
+			is.Equal(got[i][j].Z, expected[i][j].Z)
+		}
+	}
+}
+
+// bigTeam returns a team of n players all with the same mu and sigma
+func bigTeam(n int, mu, sigma float64) types.Team {
+	team := make(types.Team, n)
+	for i := range team {
+		team[i] = types.Rating{Mu: mu, Sigma: sigma, Z: 3}
+	}
+	return team
+}
+
+func assertFinite(is *_is.I, teams []types.Team) {
+	for _, team := range teams {
+		for _, r := range team {
+			is.True(!math.IsNaN(r.Mu) && !math.IsInf(r.Mu, 0))
+			is.True(!math.IsNaN(r.Sigma) && !math.IsInf(r.Sigma, 0))
+		}
+	}
+}
+
+func TestPlackettLuceHandlesLargeMu(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	options := &types.OpenSkillOptions{
+		Mu:    ptr.Float64(1500),
+		Sigma: ptr.Float64(5),
+	}
+	model := models.NewPlackettLuce(options)
+
+	// 50 players a side at mu 1500 used to overflow exp(teamMu / c)
+	high := model.Rate([]types.Team{
+		bigTeam(50, 1500, 5),
+		bigTeam(50, 1500, 5),
+		bigTeam(50, 1500, 5),
+	}, options)
+	assertFinite(is, high)
+
+	// Plackett-Luce only depends on the differences between team mus, so
+	// shifting everybody by the same amount must give the same updates
+	low := model.Rate([]types.Team{
+		bigTeam(50, 0, 5),
+		bigTeam(50, 0, 5),
+		bigTeam(50, 0, 5),
+	}, options)
+	for i := range high {
+		is.True(math.Abs((high[i][0].Mu-1500)-low[i][0].Mu) < 1e-9)
+		is.True(math.Abs(high[i][0].Sigma-low[i][0].Sigma) < 1e-9)
+	}
+	is.True(high[0][0].Mu > 1500)
+	is.True(high[2][0].Mu < 1500)
+}
+
+func TestPlackettLuceHandlesHugeTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	teams := model.Rate([]types.Team{
+		bigTeam(5000, 24, 1),
+		bigTeam(5000, 25, 1),
+	}, nil)
+	assertFinite(is, teams)
+	is.True(teams[0][0].Mu > 24)
+	is.True(teams[1][0].Mu < 25)
+}
+
+func TestPlackettLuceHandlesExtremeMuGaps(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	teams := model.Rate([]types.Team{
+		{types.Rating{Mu: -1e6, Sigma: 1, Z: 3}},
+		{types.Rating{Mu: 1e6, Sigma: 1, Z: 3}},
+	}, nil)
+	assertFinite(is, teams)
+
+	// The massive underdog won, so it gains and the favourite loses
+	is.True(teams[0][0].Mu > -1e6)
+	is.True(teams[1][0].Mu < 1e6)
+}
+
+// naivePlackettLuce is the straightforward O(n²) Plackett-Luce update, teams
+// must be sorted by rank
+func naivePlackettLuce(teams []types.Team, rank []int) []types.Team {
+	model := models.NewPlackettLuce(nil)
+	teamRatings := model.U.TeamRating(teams, &types.OpenSkillOptions{Rank: rank})
+	c := model.U.C(teamRatings)
+	a := model.U.A(teamRatings)
+
+	sumQ := make([]float64, len(teamRatings))
+	for i, iTeamRating := range teamRatings {
+		for _, qTeamRating := range teamRatings {
+			if qTeamRating.Rank >= iTeamRating.Rank {
+				sumQ[i] += math.Exp(qTeamRating.TeamMu / c)
+			}
+		}
+	}
+
+	returning := make([]types.Team, len(teams))
+	for i, iTeamRating := range teamRatings {
+		iMuOverCe := math.Exp(iTeamRating.TeamMu / c)
+		omega, delta := 0.0, 0.0
+		for q, qTeamRating := range teamRatings {
+			if qTeamRating.Rank > iTeamRating.Rank {
+				continue
+			}
+			quotient := iMuOverCe / sumQ[q]
+			if i == q {
+				omega += (1.0 - quotient) / float64(a[q])
+			} else {
+				omega -= quotient / float64(a[q])
+			}
+			delta += (quotient * (1 - quotient)) / float64(a[q])
+		}
+
+		iGamma := math.Sqrt(iTeamRating.TeamSigmaSquared) / c
+		iOmega := omega * (iTeamRating.TeamSigmaSquared / c)
+		iDelta := iGamma * delta * (iTeamRating.TeamSigmaSquared / (c * c))
+
+		returning[i] = make(types.Team, len(iTeamRating.Team))
+		for j, r := range iTeamRating.Team {
+			ratio := r.Sigma * r.Sigma / iTeamRating.TeamSigmaSquared
+			returning[i][j] = types.Rating{
+				Mu:    r.Mu + ratio*iOmega,
+				Sigma: r.Sigma * math.Sqrt(math.Max(1-ratio*iDelta, model.Kappa)),
+				Z:     r.Z,
+			}
+		}
+	}
+	return returning
+}
+
+func TestPlackettLuceMatchesNaiveImplementation(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	rng := rand.New(rand.NewSource(42))
+	model := models.NewPlackettLuce(nil)
+	for round := 0; round < 50; round++ {
+		n := 2 + rng.Intn(40)
+		teams := make([]types.Team, n)
+		rank := make([]int, n)
+		for i := range teams {
+			teams[i] = bigTeam(1+rng.Intn(3), 10+rng.Float64()*30, 1+rng.Float64()*7)
+			// non decreasing ranks, with roughly one tie every four teams
+			if i > 0 {
+				rank[i] = rank[i-1]
+				if rng.Intn(4) != 0 {
+					rank[i]++
+				}
+			}
+		}
+
+		got := model.Rate(teams, &types.OpenSkillOptions{Rank: rank})
+		expected := naivePlackettLuce(teams, rank)
+		for i := range got {
+			for j := range got[i] {
+				is.True(math.Abs(got[i][j].Mu-expected[i][j].Mu) < 1e-9)
+				is.True(math.Abs(got[i][j].Sigma-expected[i][j].Sigma) < 1e-9)
+			}
+		}
+	}
+}
+
+func TestPlackettLuceDoesNotNeedSortedTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	a := types.Team{rating.New()}
+	b := types.Team{types.Rating{Mu: 30, Sigma: 7, Z: 3}}
+	c := types.Team{types.Rating{Mu: 20, Sigma: 5, Z: 3}}
+
+	model := models.NewPlackettLuce(nil)
+	sorted := model.Rate([]types.Team{a, b, c}, &types.OpenSkillOptions{Rank: []int{0, 1, 2}})
+	shuffled := model.Rate([]types.Team{c, a, b}, &types.OpenSkillOptions{Rank: []int{2, 0, 1}})
+
+	is.Equal(shuffled, []types.Team{sorted[2], sorted[0], sorted[1]})
+}
+
+func BenchmarkPlackettLuceRate(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		teams := make([]types.Team, n)
+		rank := make([]int, n)
+		for i := range teams {
+			teams[i] = types.Team{rating.New()}
+			rank[i] = i
+		}
+		options := &types.OpenSkillOptions{Rank: rank}
+		model := models.NewPlackettLuce(nil)
+
+		b.Run(fmt.Sprintf("%dTeams", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				model.Rate(teams, options)
+			}
+		})
+	}
+}
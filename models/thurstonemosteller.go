@@ -0,0 +1,34 @@
+package models
+
+import (
+	"math"
+
+	"github.com/intinig/go-openskill/types"
+)
+
+// thurstoneMostellerPair returns the omega and delta contributions of
+// comparing the team i against the team q with the Gaussian
+// Thurstone-Mosteller model. Teams sharing a rank are updated through the
+// Gaussian doubly truncated at the draw margin.
+func (m *Constants) thurstoneMostellerPair(options *types.OpenSkillOptions, iTeamRating, qTeamRating types.TeamRating) (float64, float64) {
+	ciq := math.Sqrt(iTeamRating.TeamSigmaSquared + qTeamRating.TeamSigmaSquared + m.TwoBetaSquared)
+	deltaMu := (iTeamRating.TeamMu - qTeamRating.TeamMu) / ciq
+	sigmaSquaredToCiq := iTeamRating.TeamSigmaSquared / ciq
+	gamma := m.gamma(options, iTeamRating, ciq)
+	drawMargin := m.DrawMargin / ciq
+
+	if qTeamRating.Rank == iTeamRating.Rank {
+		omega := sigmaSquaredToCiq * m.U.Vt(deltaMu, drawMargin)
+		delta := ((gamma * sigmaSquaredToCiq) / ciq) * m.U.Wt(deltaMu, drawMargin)
+		return omega, delta
+	}
+
+	sign := -1.0
+	if qTeamRating.Rank > iTeamRating.Rank {
+		sign = 1.0
+	}
+
+	omega := sign * sigmaSquaredToCiq * m.U.V(sign*deltaMu, drawMargin)
+	delta := ((gamma * sigmaSquaredToCiq) / ciq) * m.U.W(sign*deltaMu, drawMargin)
+	return omega, delta
+}
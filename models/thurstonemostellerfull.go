@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/intinig/go-openskill/types"
+)
+
+// ThurstoneMostellerFull is a Gaussian model that compares every team against
+// every other team in the match, ties are handled through a draw margin
+type ThurstoneMostellerFull struct {
+	Constants
+}
+
+// NewThurstoneMostellerFull returns a new ThurstoneMostellerFull model
+func NewThurstoneMostellerFull(options *types.OpenSkillOptions) *ThurstoneMostellerFull {
+	return &ThurstoneMostellerFull{
+		Constants: NewConstants(options),
+	}
+}
+
+// Rate rates a set of teams
+func (t *ThurstoneMostellerFull) Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
+	// Initialize options
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	returning := make([]types.Team, len(teams))
+
+	// Create a teamRatings struct for each team
+	teamRatings := t.U.TeamRating(teams, options)
+
+	// Main loop, each team is compared against all the other teams
+	for i, iTeamRating := range teamRatings {
+		omega, delta := 0.0, 0.0
+		for q, qTeamRating := range teamRatings {
+			if i == q {
+				continue
+			}
+
+			qOmega, qDelta := t.thurstoneMostellerPair(options, iTeamRating, qTeamRating)
+			omega += t.marginFactor(options, iTeamRating, qTeamRating) * qOmega
+			delta += qDelta
+		}
+
+		returning[i] = t.updateTeam(iTeamRating, omega, delta)
+	}
+
+	return returning
+}
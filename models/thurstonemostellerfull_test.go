@@ -0,0 +1,200 @@
+package models_test
+
+import (
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestThurstoneMostellerFullSoloGameDoesNotChangeRating(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rating.New()
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{{r}}, nil)
+	is.Equal(teams, []types.Team{{r}})
+}
+
+func TestThurstoneMostellerFull2PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 29.230718708993216, Sigma: 7.630934718709003, Z: 3}},
+		{types.Rating{Mu: 20.769281291006784, Sigma: 7.630934718709003, Z: 3}},
+	})
+}
+
+func TestThurstoneMostellerFull3PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 33.46143741798643, Sigma: 6.856958868037088, Z: 3}},
+		{types.Rating{Mu: 25, Sigma: 6.856958868037088, Z: 3}},
+		{types.Rating{Mu: 16.53856258201357, Sigma: 6.856958868037088, Z: 3}},
+	})
+}
+
+func TestThurstoneMostellerFull4PlayersFreeForAll(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 37.69215612697965, Sigma: 5.983694941648218, Z: 3}},
+		{types.Rating{Mu: 29.230718708993216, Sigma: 5.983694941648218, Z: 3}},
+		{types.Rating{Mu: 20.769281291006784, Sigma: 5.983694941648218, Z: 3}},
+		{types.Rating{Mu: 12.307843873020353, Sigma: 5.983694941648218, Z: 3}},
+	})
+}
+
+func TestThurstoneMostellerFull3TeamsWithDifferentPlayersNumbers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New(), rating.New(), rating.New()},
+		{rating.New()},
+		{rating.New(), rating.New()},
+	}, nil)
+	is.Equal(teams, []types.Team{
+		{
+			types.Rating{Mu: 25.729796801442728, Sigma: 8.153169236399172, Z: 3},
+			types.Rating{Mu: 25.729796801442728, Sigma: 8.153169236399172, Z: 3},
+			types.Rating{Mu: 25.729796801442728, Sigma: 8.153169236399172, Z: 3},
+		},
+		{types.Rating{Mu: 34.02513843037207, Sigma: 7.757460494129447, Z: 3}},
+		{
+			types.Rating{Mu: 15.245064768185204, Sigma: 7.372121080126496, Z: 3},
+			types.Rating{Mu: 15.245064768185204, Sigma: 7.372121080126496, Z: 3},
+		},
+	})
+}
+
+func TestThurstoneMostellerFullHandlesTiesWithADrawMargin(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+		{rating.New()},
+	}, &types.OpenSkillOptions{
+		Rank: []int{0, 0, 2},
+	})
+	is.Equal(teams, []types.Team{
+		{types.Rating{Mu: 29.230718708993216, Sigma: 6.376777616090063, Z: 3}},
+		{types.Rating{Mu: 29.230718708993216, Sigma: 6.376777616090063, Z: 3}},
+		{types.Rating{Mu: 16.53856258201357, Sigma: 6.856958868037088, Z: 3}},
+	})
+}
+
+func TestThurstoneMostellerFullDrawBetweenEqualTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+	teams := model.Rate([]types.Team{
+		{rating.New()},
+		{rating.New()},
+	}, &types.OpenSkillOptions{
+		Rank: []int{0, 0},
+	})
+
+	// A draw between equals tells nothing about who is better, so mu stays
+	// put, and both players become more certain by the same amount
+	is.Equal(teams[0][0].Mu, 25.0)
+	is.Equal(teams[1][0].Mu, 25.0)
+	is.True(teams[0][0].Sigma < rating.New().Sigma)
+	is.Equal(teams[0][0].Sigma, teams[1][0].Sigma)
+}
+
+func TestThurstoneMostellerFullSeries(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	model := models.NewThurstoneMostellerFull(nil)
+
+	p00 := rating.New()
+	p10 := rating.New()
+	p20 := rating.New()
+	p30 := rating.New()
+	p40 := rating.New()
+
+	m1 := rating.Rate([]types.Team{
+		{p00}, {p10}, {p20}, {p30}, {p40},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 7, 7, 5, 5},
+	})
+
+	p01 := m1[0][0]
+	p11 := m1[1][0]
+	p21 := m1[2][0]
+	p31 := m1[3][0]
+	p41 := m1[4][0]
+
+	p02 := p01
+	p32 := p31
+
+	m2 := rating.Rate([]types.Team{
+		{p41}, {p21}, {p11},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 5, 5},
+	})
+
+	p42 := m2[0][0]
+	p22 := m2[1][0]
+	p12 := m2[2][0]
+
+	p43 := p42
+
+	m3 := rating.Rate([]types.Team{
+		{p32}, {p12}, {p22}, {p02},
+	}, &types.OpenSkillOptions{
+		Model: model,
+		Score: []int{9, 9, 7, 7},
+	})
+
+	p33 := m3[0][0]
+	p13 := m3[1][0]
+	p23 := m3[2][0]
+	p03 := m3[3][0]
+
+	is.Equal(p03.Mu, 18.688151936464827)
+	is.Equal(p03.Sigma, 3.374349118763375)
+	is.Equal(p13.Mu, 27.015046201348873)
+	is.Equal(p13.Sigma, 3.2500374462974135)
+	is.Equal(p23.Mu, 22.82579642143709)
+	is.Equal(p23.Sigma, 3.261729293640099)
+	is.Equal(p33.Mu, 27.281608224610032)
+	is.Equal(p33.Sigma, 3.3870101210570542)
+	is.Equal(p43.Mu, 22.63333429289691)
+	is.Equal(p43.Sigma, 3.747504435966865)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/intinig/go-openskill/types"
+)
+
+// ThurstoneMostellerPart is a Gaussian model that only compares each team
+// against its neighbours in the ranking, which keeps it cheap for matches with
+// a lot of teams
+type ThurstoneMostellerPart struct {
+	Constants
+}
+
+// NewThurstoneMostellerPart returns a new ThurstoneMostellerPart model
+func NewThurstoneMostellerPart(options *types.OpenSkillOptions) *ThurstoneMostellerPart {
+	return &ThurstoneMostellerPart{
+		Constants: NewConstants(options),
+	}
+}
+
+// Rate rates a set of teams, teams are expected to be sorted by rank as
+// rating.Rate does
+func (t *ThurstoneMostellerPart) Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
+	// Initialize options
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	returning := make([]types.Team, len(teams))
+
+	// Create a teamRatings struct for each team
+	teamRatings := t.U.TeamRating(teams, options)
+
+	// Each team is only compared against the teams adjacent to it
+	adjacentTeams := t.U.LadderPairs(teamRatings)
+
+	for i, iTeamRating := range teamRatings {
+		omega, delta := 0.0, 0.0
+		for _, qTeamRating := range adjacentTeams[i] {
+			qOmega, qDelta := t.thurstoneMostellerPair(options, iTeamRating, qTeamRating)
+			omega += t.marginFactor(options, iTeamRating, qTeamRating) * qOmega
+			delta += qDelta
+		}
+
+		returning[i] = t.updateTeam(iTeamRating, omega, delta)
+	}
+
+	return returning
+}
@@ -0,0 +1,274 @@
+// Package openskill is the entry point of go-openskill. It bundles the
+// hyperparameters of a rating system in an immutable Config, built with
+// functional options such as WithMu or WithBeta.
+package openskill
+
+import (
+	"time"
+
+	"github.com/intinig/go-openskill/defaults"
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// Model selects the rating model used by a Config
+type Model int
+
+const (
+	// PlackettLuce is the default model
+	PlackettLuce Model = iota
+	BradleyTerryFull
+	BradleyTerryPart
+	ThurstoneMostellerFull
+	ThurstoneMostellerPart
+)
+
+// Option configures a Config
+type Option func(*Config)
+
+// WithMu sets the mean of a new rating, the default value is 25
+func WithMu(mu float64) Option {
+	return func(c *Config) { c.mu = &mu }
+}
+
+// WithSigma sets the standard deviation of a new rating, the default value is
+// mu / z
+func WithSigma(sigma float64) Option {
+	return func(c *Config) { c.sigma = &sigma }
+}
+
+// WithZ sets the number of standard deviations used by Ordinal, the default
+// value is 3
+func WithZ(z int) Option {
+	return func(c *Config) { c.z = &z }
+}
+
+// WithBeta sets the performance variability of the players, the default value
+// is sigma / 2
+func WithBeta(beta float64) Option {
+	return func(c *Config) { c.beta = &beta }
+}
+
+// WithEpsilon sets the small number that keeps the models numerically stable,
+// the default value is 0.0001
+func WithEpsilon(epsilon float64) Option {
+	return func(c *Config) { c.epsilon = &epsilon }
+}
+
+// WithDrawMargin sets the draw margin of the Thurstone-Mosteller models, the
+// default value is 0.1
+func WithDrawMargin(drawMargin float64) Option {
+	return func(c *Config) { c.drawMargin = &drawMargin }
+}
+
+// WithKappa sets the lower bound of the factor used to shrink sigma, the
+// default value is epsilon
+func WithKappa(kappa float64) Option {
+	return func(c *Config) { c.kappa = &kappa }
+}
+
+// WithTau sets the variance added to every player before each match
+func WithTau(tau float64) Option {
+	return func(c *Config) { c.tau = &tau }
+}
+
+// WithPreventSigmaIncrease stops Tau from making sigma larger after a match
+func WithPreventSigmaIncrease() Option {
+	return func(c *Config) { c.preventSigmaIncrease = true }
+}
+
+// WithGamma sets the function returning the dynamic factor of a team
+func WithGamma(gamma func(types.TeamRating) float64) Option {
+	return func(c *Config) { c.gamma = gamma }
+}
+
+// WithMargin enables the margin-of-victory mode, see
+// types.OpenSkillOptions.Margin
+func WithMargin(margin float64) Option {
+	return func(c *Config) { c.margin = &margin }
+}
+
+// WithLowerIsBetter ranks teams with lower scores first
+func WithLowerIsBetter() Option {
+	return func(c *Config) { c.lowerIsBetter = true }
+}
+
+// WithDecay inflates the sigma of players that have been inactive, it is
+// applied to matches played with a Now
+func WithDecay(decay types.SigmaDecay) Option {
+	return func(c *Config) { c.decay = decay }
+}
+
+// WithModel sets the rating model, the default value is PlackettLuce
+func WithModel(model Model) Option {
+	return func(c *Config) { c.model = model }
+}
+
+// Config holds the hyperparameters of a rating system. Its defaults are
+// resolved once by New, and it cannot be changed afterwards, so it is safe to
+// share between goroutines.
+type Config struct {
+	mu, sigma, beta, epsilon, kappa *float64
+	drawMargin                      *float64
+	z                               *int
+	tau, margin                     *float64
+	preventSigmaIncrease            bool
+	lowerIsBetter                   bool
+	gamma                           func(types.TeamRating) float64
+	decay                           types.SigmaDecay
+	model                           Model
+
+	ratingModel types.RatingModel
+}
+
+// New returns a new Config with the given options applied on top of the
+// defaults
+func New(options ...Option) *Config {
+	c := &Config{}
+	for _, option := range options {
+		option(c)
+	}
+
+	v := defaults.Resolve(&types.OpenSkillOptions{
+		Mu:         c.mu,
+		Sigma:      c.sigma,
+		Z:          c.z,
+		Beta:       c.beta,
+		Epsilon:    c.epsilon,
+		Kappa:      c.kappa,
+		DrawMargin: c.drawMargin,
+	})
+	c.mu = &v.Mu
+	c.sigma = &v.Sigma
+	c.z = &v.Z
+	c.beta = &v.Beta
+	c.epsilon = &v.Epsilon
+	c.kappa = &v.Kappa
+	c.drawMargin = &v.DrawMargin
+
+	resolved := c.Options()
+	switch c.model {
+	case BradleyTerryFull:
+		c.ratingModel = models.NewBradleyTerryFull(resolved)
+	case BradleyTerryPart:
+		c.ratingModel = models.NewBradleyTerryPart(resolved)
+	case ThurstoneMostellerFull:
+		c.ratingModel = models.NewThurstoneMostellerFull(resolved)
+	case ThurstoneMostellerPart:
+		c.ratingModel = models.NewThurstoneMostellerPart(resolved)
+	default:
+		c.ratingModel = models.NewPlackettLuce(resolved)
+	}
+
+	return c
+}
+
+// Mu returns the mean of a new rating
+func (c *Config) Mu() float64 { return *c.mu }
+
+// Sigma returns the standard deviation of a new rating
+func (c *Config) Sigma() float64 { return *c.sigma }
+
+// Z returns the number of standard deviations used by Ordinal
+func (c *Config) Z() int { return *c.z }
+
+// Beta returns the performance variability of the players
+func (c *Config) Beta() float64 { return *c.beta }
+
+// Epsilon returns the small number that keeps the models numerically stable
+func (c *Config) Epsilon() float64 { return *c.epsilon }
+
+// DrawMargin returns the draw margin of the Thurstone-Mosteller models
+func (c *Config) DrawMargin() float64 { return *c.drawMargin }
+
+// Kappa returns the lower bound of the factor used to shrink sigma
+func (c *Config) Kappa() float64 { return *c.kappa }
+
+// Options returns the resolved configuration as a new types.OpenSkillOptions,
+// for use with the lower level packages. Every call returns a fresh copy, so it
+// can be modified freely.
+func (c *Config) Options() *types.OpenSkillOptions {
+	return &types.OpenSkillOptions{
+		Mu:                   copyPtr(c.mu),
+		Sigma:                copyPtr(c.sigma),
+		Z:                    copyPtr(c.z),
+		Beta:                 copyPtr(c.beta),
+		Epsilon:              copyPtr(c.epsilon),
+		Kappa:                copyPtr(c.kappa),
+		DrawMargin:           copyPtr(c.drawMargin),
+		Tau:                  copyPtr(c.tau),
+		Margin:               copyPtr(c.margin),
+		PreventSigmaIncrease: c.preventSigmaIncrease,
+		LowerIsBetter:        c.lowerIsBetter,
+		Gamma:                c.gamma,
+		Decay:                c.decay,
+		Model:                c.ratingModel,
+	}
+}
+
+// NewRating returns a new rating
+func (c *Config) NewRating() types.Rating {
+	return rating.NewWithOptions(c.Options())
+}
+
+// Match holds the outcome of a match, see types.OpenSkillOptions for the
+// meaning of each field. Teams are ranked in the order they are given when
+// neither Rank nor scores are set.
+type Match struct {
+	Rank   []int
+	Score  []int
+	Scores []float64
+	Weight [][]float64
+	Now    time.Time
+}
+
+// Rate rates teams after a match, see rating.RateE
+func (c *Config) Rate(teams []types.Team, match Match) ([]types.Team, error) {
+	options := c.Options()
+	options.Rank = match.Rank
+	options.Score = match.Score
+	options.Scores = match.Scores
+	options.Weight = match.Weight
+	options.Now = match.Now
+
+	return rating.RateE(teams, options)
+}
+
+// PredictWin returns the probability of each team winning, see
+// rating.PredictWinE
+func (c *Config) PredictWin(teams []types.Team) ([]float64, error) {
+	return rating.PredictWinE(teams, c.Options())
+}
+
+// PredictDraw returns the probability of the teams drawing, see
+// rating.PredictDrawE
+func (c *Config) PredictDraw(teams []types.Team) (float64, error) {
+	return rating.PredictDrawE(teams, c.Options())
+}
+
+// PredictRank returns the most likely rank of each team and its probability,
+// see rating.PredictRankE
+func (c *Config) PredictRank(teams []types.Team) ([]int64, []float64, error) {
+	return rating.PredictRankE(teams, c.Options())
+}
+
+// PredictRankDistribution simulates the match and returns how likely each
+// team is to finish in each position, see rating.PredictRankDistributionE
+func (c *Config) PredictRankDistribution(teams []types.Team, simulation *rating.SimulationOptions) (rating.RankDistribution, error) {
+	return rating.PredictRankDistributionE(teams, c.Options(), simulation)
+}
+
+// MatchQuality returns how balanced a match between teams is, see
+// rating.MatchQualityE
+func (c *Config) MatchQuality(teams []types.Team) (float64, error) {
+	return rating.MatchQualityE(teams, c.Options())
+}
+
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,115 @@
+package openskill_test
+
+import (
+	"errors"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill"
+	"github.com/intinig/go-openskill/models"
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestNewResolvesDefaults(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	c := openskill.New()
+	is.Equal(c.Mu(), 25.0)
+	is.Equal(c.Sigma(), 25/3.0)
+	is.Equal(c.Z(), 3)
+	is.Equal(c.Beta(), 25/6.0)
+	is.Equal(c.Epsilon(), 0.0001)
+	is.Equal(c.Kappa(), 0.0001)
+	is.Equal(c.DrawMargin(), 0.1)
+	is.Equal(c.NewRating(), rating.New())
+}
+
+func TestNewDerivesDefaultsFromOptions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	c := openskill.New(openskill.WithMu(30), openskill.WithZ(2), openskill.WithEpsilon(0.1))
+	is.Equal(c.Sigma(), 15.0)
+	is.Equal(c.Beta(), 7.5)
+	is.Equal(c.Kappa(), 0.1)
+
+	c = openskill.New(openskill.WithSigma(4), openskill.WithBeta(3), openskill.WithKappa(0.5), openskill.WithDrawMargin(1))
+	is.Equal(c.Mu(), 25.0)
+	is.Equal(c.DrawMargin(), 1.0)
+	is.Equal(*c.Options().DrawMargin, 1.0)
+	is.Equal(c.Beta(), 3.0)
+	is.Equal(c.Kappa(), 0.5)
+	is.Equal(c.NewRating(), types.Rating{Mu: 25, Sigma: 4, Z: 3})
+}
+
+func TestOptionsReturnsAFreshCopy(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	c := openskill.New(openskill.WithMu(30))
+	options := c.Options()
+	*options.Mu = 10
+	options.Rank = []int{1, 0}
+
+	is.Equal(c.Mu(), 30.0)
+	is.Equal(*c.Options().Mu, 30.0)
+	is.Equal(c.Options().Rank, nil)
+}
+
+func TestConfigRateMatchesRate(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{{test.Teams["a1"], test.Teams["b1"]}, {test.Teams["c1"]}}
+	c := openskill.New(
+		openskill.WithSigma(5),
+		openskill.WithTau(0.3),
+		openskill.WithModel(openskill.BradleyTerryFull),
+	)
+
+	rated, err := c.Rate(teams, openskill.Match{Rank: []int{1, 0}, Weight: [][]float64{{1, 0.5}, {1}}})
+	is.NoErr(err)
+
+	options := &types.OpenSkillOptions{
+		Sigma:  ptr.Float64(5),
+		Tau:    ptr.Float64(0.3),
+		Rank:   []int{1, 0},
+		Weight: [][]float64{{1, 0.5}, {1}},
+	}
+	options.Model = models.NewBradleyTerryFull(options)
+	is.Equal(rated, rating.Rate(teams, options))
+}
+
+func TestConfigPredictsWithTheResolvedBeta(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+	c := openskill.New(openskill.WithSigma(5))
+	explicit := &types.OpenSkillOptions{Beta: ptr.Float64(2.5)}
+
+	win, err := c.PredictWin(teams)
+	is.NoErr(err)
+	is.Equal(win, rating.PredictWin(teams, explicit))
+
+	draw, err := c.PredictDraw(teams)
+	is.NoErr(err)
+	is.Equal(draw, rating.PredictDraw(teams, explicit))
+}
+
+func TestConfigReturnsErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	c := openskill.New()
+	_, err := c.Rate(nil, openskill.Match{})
+	is.True(errors.Is(err, rating.ErrNoTeams))
+
+	_, err = c.PredictWin([]types.Team{{}})
+	is.True(errors.Is(err, rating.ErrEmptyTeam))
+}
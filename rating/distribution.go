@@ -0,0 +1,169 @@
+package rating
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"gonum.org/v1/gonum/stat/distuv"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/types"
+	"github.com/intinig/go-openskill/util"
+)
+
+// DefaultSamples is the number of matches simulated by PredictRankDistribution
+// when no sample count is given
+const DefaultSamples = 10000
+
+// DefaultConfidence is the level of the intervals returned by
+// PredictRankDistribution when no confidence is given
+const DefaultConfidence = 0.95
+
+// SimulationOptions controls a Monte Carlo simulation
+type SimulationOptions struct {
+	// Samples is the number of simulated matches, the default value is
+	// DefaultSamples
+	Samples int
+	// Rand is the source of randomness, seeding it makes the simulation
+	// reproducible. A randomly seeded source is used when it is nil.
+	Rand *rand.Rand
+	// Confidence is the level of the intervals, between 0 and 1. The default
+	// value is DefaultConfidence.
+	Confidence float64
+}
+
+// RankDistribution is the outcome of simulating a match many times. Ranks
+// start from 1, like the ones returned by PredictRank.
+type RankDistribution struct {
+	// Probabilities holds, for each team, the probability of finishing in
+	// each position: Probabilities[i][k] is the probability of team i
+	// finishing with rank k+1
+	Probabilities [][]float64
+	// ExpectedRank is the average rank of each team
+	ExpectedRank []float64
+	// ExpectedRankInterval is the confidence interval of the estimate of
+	// ExpectedRank, it shrinks as the number of samples grows
+	ExpectedRankInterval [][2]float64
+	// RankInterval is the range of ranks each team finishes in with the
+	// requested confidence, leaving out the same share of outcomes on each
+	// side
+	RankInterval [][2]int
+}
+
+// PredictRankDistribution simulates the match by sampling the performance of
+// every team from its Gaussian, the same that PredictWin is based on, and
+// returns how the teams finished
+func PredictRankDistribution(teams []types.Team, options *types.OpenSkillOptions, simulation *SimulationOptions) RankDistribution {
+	if simulation == nil {
+		simulation = &SimulationOptions{}
+	}
+
+	samples := simulation.Samples
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+
+	r := simulation.Rand
+	if r == nil {
+		r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	confidence := simulation.Confidence
+	if confidence <= 0 || confidence >= 1 {
+		confidence = DefaultConfidence
+	}
+
+	// Initialize util, used for teamRatings
+	_, betaSquared := getBetas(options)
+	u := util.NewWithOptions(&util.Options{
+		BetaSquared: ptr.Float64(betaSquared),
+	})
+
+	teamRatings := u.TeamRating(teams, options)
+	n := len(teamRatings)
+
+	sigmas := make([]float64, n)
+	for i, teamRating := range teamRatings {
+		sigmas[i] = math.Sqrt(performanceVariance(teamRating, betaSquared))
+	}
+
+	// counts[i][k] is how many times team i finished in position k
+	counts := make([][]int, n)
+	for i := range counts {
+		counts[i] = make([]int, n)
+	}
+	sums := make([]float64, n)
+	squares := make([]float64, n)
+
+	performances := make([]float64, n)
+	order := make([]int, n)
+	for s := 0; s < samples; s++ {
+		for i, teamRating := range teamRatings {
+			performances[i] = teamRating.TeamMu + sigmas[i]*r.NormFloat64()
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return performances[order[a]] > performances[order[b]]
+		})
+
+		for k, i := range order {
+			counts[i][k]++
+			rank := float64(k + 1)
+			sums[i] += rank
+			squares[i] += rank * rank
+		}
+	}
+
+	z := distuv.UnitNormal.Quantile(0.5 + confidence/2)
+	tail := (1 - confidence) / 2
+
+	distribution := RankDistribution{
+		Probabilities:        make([][]float64, n),
+		ExpectedRank:         make([]float64, n),
+		ExpectedRankInterval: make([][2]float64, n),
+		RankInterval:         make([][2]int, n),
+	}
+	for i := range teamRatings {
+		probabilities := make([]float64, n)
+		for k, count := range counts[i] {
+			probabilities[k] = float64(count) / float64(samples)
+		}
+		distribution.Probabilities[i] = probabilities
+
+		mean := sums[i] / float64(samples)
+		variance := math.Max(squares[i]/float64(samples)-mean*mean, 0)
+		margin := z * math.Sqrt(variance/float64(samples))
+		distribution.ExpectedRank[i] = mean
+		distribution.ExpectedRankInterval[i] = [2]float64{mean - margin, mean + margin}
+		distribution.RankInterval[i] = rankInterval(probabilities, tail)
+	}
+
+	return distribution
+}
+
+// rankInterval returns the lowest and highest ranks left once the given share
+// of outcomes has been removed from each end of probabilities
+func rankInterval(probabilities []float64, tail float64) [2]int {
+	interval := [2]int{1, len(probabilities)}
+
+	cumulative := 0.0
+	for k, p := range probabilities {
+		cumulative += p
+		if cumulative > tail {
+			interval[0] = k + 1
+			break
+		}
+	}
+
+	cumulative = 0.0
+	for k := len(probabilities) - 1; k >= 0; k-- {
+		cumulative += probabilities[k]
+		if cumulative > tail {
+			interval[1] = k + 1
+			break
+		}
+	}
+
+	return interval
+}
@@ -0,0 +1,109 @@
+package rating_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+// seeded returns simulation options with a fixed seed
+func seeded(samples int) *rating.SimulationOptions {
+	return &rating.SimulationOptions{
+		Samples: samples,
+		Rand:    rand.New(rand.NewPCG(1, 2)),
+	}
+}
+
+func TestPredictRankDistributionIsAProbabilityMatrix(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{
+		{test.PredictWinTeams["a1"], test.PredictWinTeams["a2"]},
+		{test.PredictWinTeams["b2"]},
+		{test.PredictWinTeams["a2"]},
+		{test.PredictWinTeams["a1"]},
+	}
+	distribution := rating.PredictRankDistribution(teams, nil, seeded(5000))
+
+	for i := range teams {
+		row, column := 0.0, 0.0
+		for k := range teams {
+			row += distribution.Probabilities[i][k]
+			column += distribution.Probabilities[k][i]
+		}
+		is.True(almostEqual(row, 1, 1e-9))
+		is.True(almostEqual(column, 1, 1e-9))
+	}
+}
+
+func TestPredictRankDistributionIsReproducible(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+	is.Equal(
+		rating.PredictRankDistribution(teams, nil, seeded(1000)),
+		rating.PredictRankDistribution(teams, nil, seeded(1000)),
+	)
+}
+
+func TestPredictRankDistributionMatchesPredictWin(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{
+		{test.Teams["a1"], test.Teams["c1"]},
+		{test.Teams["b1"], test.Teams["d1"]},
+	}
+	options := &types.OpenSkillOptions{Sigma: ptr.Float64(5)}
+	distribution := rating.PredictRankDistribution(teams, options, seeded(100000))
+	win := rating.PredictWin(teams, options)
+
+	is.True(almostEqual(distribution.Probabilities[0][0], win[0], 0.005))
+	is.True(almostEqual(distribution.Probabilities[1][0], win[1], 0.005))
+	is.True(almostEqual(distribution.ExpectedRank[0], 2-win[0], 0.005))
+}
+
+func TestPredictRankDistributionIntervals(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	strong := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(100), Sigma: ptr.Float64(1)})
+	r := rating.New()
+	teams := []types.Team{{r}, {strong}, {r}, {r}}
+	distribution := rating.PredictRankDistribution(teams, nil, seeded(20000))
+
+	// The strong team always wins
+	is.Equal(distribution.Probabilities[1][0], 1.0)
+	is.Equal(distribution.ExpectedRank[1], 1.0)
+	is.Equal(distribution.ExpectedRankInterval[1], [2]float64{1, 1})
+	is.Equal(distribution.RankInterval[1], [2]int{1, 1})
+
+	// The others are interchangeable, and share the remaining places
+	for _, i := range []int{0, 2, 3} {
+		is.True(almostEqual(distribution.ExpectedRank[i], 3, 0.05))
+		is.True(distribution.ExpectedRankInterval[i][0] < distribution.ExpectedRank[i])
+		is.True(distribution.ExpectedRankInterval[i][1] > distribution.ExpectedRank[i])
+		is.Equal(distribution.RankInterval[i], [2]int{2, 4})
+	}
+}
+
+func TestPredictRankDistributionForOneTeam(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	distribution := rating.PredictRankDistribution([]types.Team{{rating.New()}}, nil, nil)
+	is.Equal(distribution, rating.RankDistribution{
+		Probabilities:        [][]float64{{1}},
+		ExpectedRank:         []float64{1},
+		ExpectedRankInterval: [][2]float64{{1, 1}},
+		RankInterval:         [][2]int{{1, 1}},
+	})
+}
@@ -1,6 +1,8 @@
 package rating
 
 import (
+	"time"
+
 	"github.com/montanaflynn/stats"
 
 	"github.com/intinig/go-openskill/types"
@@ -10,6 +12,16 @@ func Ordinal(r types.Rating) float64 {
 	return r.Mu - float64(r.Z)*r.Sigma
 }
 
+// OrdinalAt is like Ordinal, but it applies decay to the rating up to now
+// first, so that players who have been away rank lower until they play again
+func OrdinalAt(r types.Rating, decay types.SigmaDecay, now time.Time) float64 {
+	if decay != nil {
+		r = decay.Decay(r, now)
+	}
+
+	return Ordinal(r)
+}
+
 func TeamOrdinal(t types.TeamRating) float64 {
 	teamOrdinals := make([]float64, len(t.Team))
 	for i, r := range t.Team {
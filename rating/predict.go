@@ -6,92 +6,87 @@ import (
 
 	"gonum.org/v1/gonum/stat/distuv"
 
+	"github.com/intinig/go-openskill/defaults"
 	"github.com/intinig/go-openskill/ptr"
 	"github.com/intinig/go-openskill/types"
 	"github.com/intinig/go-openskill/util"
 )
 
-// getBetas returns the beta squared value, it's a helper function
+// getBetas returns beta and beta squared, it's a helper function
 func getBetas(options *types.OpenSkillOptions) (float64, float64) {
-	if options == nil {
-		options = &types.OpenSkillOptions{}
-	}
-	z := options.Z
-	if z == nil {
-		z = ptr.Int(3)
-	}
-
-	mu := options.Mu
-	if mu == nil {
-		mu = ptr.Float64(25.0)
-	}
+	beta := defaults.Resolve(options).Beta
+	return beta, beta * beta
+}
 
-	sigma := options.Sigma
-	if sigma == nil {
-		sigma = ptr.Float64(*mu / float64(*z))
-	}
+// performanceVariance returns the variance of the performance of a team in a
+// match: the uncertainty on the skill of its players plus beta squared of
+// performance noise, once per team, the same way the rating models compare
+// two teams
+func performanceVariance(teamRating types.TeamRating, betaSquared float64) float64 {
+	return teamRating.TeamSigmaSquared + betaSquared
+}
 
-	beta := options.Beta
-	if beta == nil {
-		beta = ptr.Float64(*sigma / 2.0)
-	}
+// pairwiseWin returns the probability that team a performs better than team b
+func pairwiseWin(a, b types.TeamRating, betaSquared float64) float64 {
+	sigma := math.Sqrt(performanceVariance(a, betaSquared) + performanceVariance(b, betaSquared))
+	return phiMajor((a.TeamMu - b.TeamMu) / sigma)
+}
 
-	return *beta, *beta * *beta
+// pairwiseDraw returns the probability that the performances of team a and
+// team b are within drawMargin of each other
+func pairwiseDraw(a, b types.TeamRating, betaSquared, drawMargin float64) float64 {
+	sigma := math.Sqrt(performanceVariance(a, betaSquared) + performanceVariance(b, betaSquared))
+	d := a.TeamMu - b.TeamMu
+	return phiMajor((drawMargin-d)/sigma) - phiMajor((-drawMargin-d)/sigma)
 }
 
-// PredictWin returns the probability of each team winning
-func PredictWin(teams []types.Team, options *types.OpenSkillOptions) []float64 {
+// winProbabilities returns the probability of each team beating another
+// team, averaged over all its opponents and normalized to sum up to 1
+func winProbabilities(teams []types.Team, options *types.OpenSkillOptions) []float64 {
 	// Initialize util, used for teamRatings
 	_, betaSquared := getBetas(options)
 	u := util.NewWithOptions(&util.Options{
 		BetaSquared: ptr.Float64(betaSquared),
 	})
 
-	// This is used at the end to normalize the results
-	n := float64(len(teams))
-	denom := (n * (n - 1)) / 2
-
-	// Initialize the stats helpers. This is used to calculate the CDF
-	// of the normal distribution
-	normal := distuv.Normal{
-		Mu:    0,
-		Sigma: 1,
-	}
-
 	// Pre-calculate the team ratings
 	teamRatings := u.TeamRating(teams, options)
 
-	// Initialize the results
-	returning := make([]float64, len(teams))
+	// Every pair of teams contributes 1 in total, split between the two
+	n := float64(len(teams))
+	denom := (n * (n - 1)) / 2
 
-	// Outer loop, iterate over each team
-	for i, outerTeamRating := range teamRatings {
-		// Initialize the inner loop and run it
-		var innerTeamRatings []types.TeamRating
-		for j, innerTeamRating := range teamRatings {
+	returning := make([]float64, len(teams))
+	for i, a := range teamRatings {
+		var prediction float64
+		for j, b := range teamRatings {
 			if i != j {
-				innerTeamRatings = append(innerTeamRatings, innerTeamRating)
+				prediction += pairwiseWin(a, b, betaSquared)
 			}
 		}
 
-		var prediction float64
-		for _, innerTeamRating := range innerTeamRatings {
-			muA := outerTeamRating.TeamMu
-			muB := innerTeamRating.TeamMu
-			betaSQ := u.BetaSquared
-			sigmaSqA := outerTeamRating.TeamSigmaSquared
-			sigmaSqB := innerTeamRating.TeamSigmaSquared
-
-			prediction += normal.CDF((muA - muB) / math.Sqrt(n*betaSQ+sigmaSqA*sigmaSqA+sigmaSqB*sigmaSqB))
-		}
-
 		returning[i] = prediction / denom
 	}
 
 	return returning
 }
 
-// PredictDraw returns the probability of each team drawing
+// PredictWin returns the probability of each team winning. With two teams
+// this is the probability of each team performing better than the other,
+// with more teams it is the average of those pairwise probabilities,
+// normalized so that they sum up to 1.
+func PredictWin(teams []types.Team, options *types.OpenSkillOptions) []float64 {
+	// If there is only one team, it will always win
+	if len(teams) == 1 {
+		return []float64{1.0}
+	}
+
+	return winProbabilities(teams, options)
+}
+
+// PredictDraw returns the probability of the teams drawing, which is the
+// probability of the performances of two teams being within the draw margin,
+// averaged over all pairs of teams
 func PredictDraw(teams []types.Team, options *types.OpenSkillOptions) float64 {
 	if len(teams) == 1 {
 		return 1.0
@@ -103,56 +98,23 @@ func PredictDraw(teams []types.Team, options *types.OpenSkillOptions) float64 {
 		BetaSquared: ptr.Float64(betaSquared),
 	})
 
-	// This is used at the end to normalize the results
-	n := float64(len(teams))
-	denom := n * (n - 1)
-	if n <= 2 {
-		denom /= 2
-	}
-
-	// Initialize the stats helpers. This is used to calculate the CDF
-	// of the normal distribution
-	normal := distuv.Normal{
-		Mu:    0,
-		Sigma: 1,
-	}
-
 	// Pre-calculate the team ratings
 	teamRatings := u.TeamRating(teams, options)
 
+	// The draw margin grows with the number of players, and shrinks with the
+	// number of teams
+	n := float64(len(teams))
 	flattenedLength := float64(len(flattenTeams(teams)))
-	drawMargin := math.Sqrt(flattenedLength) * beta * normal.Quantile((1+1/n)/2)
+	drawMargin := math.Sqrt(flattenedLength) * beta * distuv.UnitNormal.Quantile((1+1/n)/2)
 
-	// Initialize the results
-	returning := make([]float64, len(teams))
-	for i, outerTeamRating := range teamRatings {
-		var innerTeamRatings []types.TeamRating
-		for j, innerTeamRating := range teamRatings {
-			if i != j {
-				innerTeamRatings = append(innerTeamRatings, innerTeamRating)
-			}
+	var prediction float64
+	for i := range teamRatings {
+		for j := i + 1; j < len(teamRatings); j++ {
+			prediction += pairwiseDraw(teamRatings[i], teamRatings[j], betaSquared, drawMargin)
 		}
-
-		var prediction float64
-		for _, innerTeamRating := range innerTeamRatings {
-			muA := outerTeamRating.TeamMu
-			muB := innerTeamRating.TeamMu
-			betaSQ := u.BetaSquared
-			sigmaSqA := outerTeamRating.TeamSigmaSquared
-			sigmaSqB := innerTeamRating.TeamSigmaSquared
-			sigmaBar := math.Sqrt(n*betaSQ + sigmaSqA*sigmaSqA + sigmaSqB*sigmaSqB)
-			prediction += normal.CDF((drawMargin-muA+muB)/sigmaBar) -
-				normal.CDF((muA-muB-drawMargin)/sigmaBar)
-		}
-
-		returning[i] = prediction
 	}
 
-	sumReturning := 0.0
-	for _, v := range returning {
-		sumReturning += v
-	}
-	return math.Abs(sumReturning) / denom
+	return prediction / (n * (n - 1) / 2)
 }
 
 // PredictRank returns the probability of each team ranking
@@ -162,41 +124,8 @@ func PredictRank(teams []types.Team, options *types.OpenSkillOptions) ([]int64,
 		return []int64{1}, []float64{1.0}
 	}
 
-	// Initialize util, used for teamRatings
-	beta, betaSquared := getBetas(options)
-	u := util.NewWithOptions(&util.Options{
-		BetaSquared: ptr.Float64(betaSquared),
-	})
-
-	// Pre-calculate the team ratings
-	teamRatings := u.TeamRating(teams, options)
-
 	n := len(teams)
-	winProbabilities := make([]float64, n)
-
-	// Calculate win probabilities for each team
-	for i, teamI := range teamRatings {
-		teamWinProbability := 0.0
-		for j, teamJ := range teamRatings {
-			if i != j {
-				teamWinProbability += phiMajor(
-					(teamI.TeamMu - teamJ.TeamMu) /
-						math.Sqrt(2*beta*beta+teamI.TeamSigmaSquared+teamJ.TeamSigmaSquared),
-				)
-			}
-		}
-		winProbabilities[i] = teamWinProbability / float64(n-1)
-	}
-
-	// Normalize probabilities
-	totalProbability := 0.0
-	for _, p := range winProbabilities {
-		totalProbability += p
-	}
-	normalizedProbabilities := make([]float64, n)
-	for i, p := range winProbabilities {
-		normalizedProbabilities[i] = p / totalProbability
-	}
+	normalizedProbabilities := winProbabilities(teams, options)
 
 	// Sort teams by probabilities in descending order
 	type teamProbability struct {
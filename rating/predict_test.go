@@ -2,6 +2,7 @@ package rating_test
 
 import (
 	"math"
+	"math/rand/v2"
 	"testing"
 
 	_is "github.com/matryer/is"
@@ -27,7 +28,7 @@ func TestPredictsWinOutcomeForTwoTeams(t *testing.T) {
 	}
 
 	probs := rating.PredictWin(teams, nil)
-	is.Equal(probs, []float64{0.34641823958165474, 0.6535817604183453})
+	is.Equal(probs, []float64{0.0008308945244597798, 0.9991691054755403})
 }
 
 func TestPredictsWinIgnoresRankings(t *testing.T) {
@@ -77,7 +78,7 @@ func TestPredictsWinOutcomeForMultipleAsymmetricTeams(t *testing.T) {
 	}
 
 	probs := rating.PredictWin(teams, nil)
-	is.Equal(probs, []float64{0.26135159416422216, 0.4111743094338915, 0.17509059831123944, 0.15238349809064683})
+	is.Equal(probs, []float64{0.32856585967958823, 0.499861517574302, 0.13155960698065075, 0.04001301576545904})
 }
 
 func TestPredictsWinWith3PlayerNewbieFFA(t *testing.T) {
@@ -168,7 +169,7 @@ func TestPredictsWinWith5PlayerNewbieFFAWithImpostor(t *testing.T) {
 	}
 
 	probs := rating.PredictWin(teams, nil)
-	is.Equal(probs, []float64{0.19603741652263795, 0.19603741652263795, 0.19603741652263795, 0.2158503339094482, 0.19603741652263795})
+	is.Equal(probs, []float64{0.17572488468641098, 0.17572488468641098, 0.17572488468641098, 0.2971004612543561, 0.17572488468641098})
 }
 
 func TestPredictDraw100PercentForSolitare(t *testing.T) {
@@ -184,7 +185,7 @@ func TestPredictDraw100PercentForSolitare(t *testing.T) {
 	is.Equal(probs, 1.0)
 }
 
-func TestPredictDraw100PercentForSelfVsSelf(t *testing.T) {
+func TestPredictDrawForSelfVsSelf(t *testing.T) {
 	t.Parallel()
 	is := _is.New(t)
 	teams := []types.Team{
@@ -196,8 +197,12 @@ func TestPredictDraw100PercentForSelfVsSelf(t *testing.T) {
 		},
 	}
 
+	// The draw margin is set so that two equal and certain players draw half
+	// of the time. PredictDraw used to return 1 here because it counted the
+	// only pair of a two team match twice, it now averages over pairs of teams
+	// whatever their number.
 	probs := rating.PredictDraw(teams, nil)
-	is.Equal(probs, 1.0)
+	is.Equal(probs, 0.4999999998765418)
 }
 
 func TestPredictDrawForTwoTeams(t *testing.T) {
@@ -215,7 +220,7 @@ func TestPredictDrawForTwoTeams(t *testing.T) {
 	}
 
 	probs := rating.PredictDraw(teams, nil)
-	is.Equal(probs, 0.1260703143635969)
+	is.Equal(probs, 0.3901307198539268)
 }
 
 func TestPredictDrawForThreeAsymmetricTeams(t *testing.T) {
@@ -242,7 +247,7 @@ func TestPredictDrawForThreeAsymmetricTeams(t *testing.T) {
 	}
 
 	probs := rating.PredictDraw(teams, nil)
-	is.Equal(probs, 0.04322122887507539)
+	is.Equal(probs, 0.08440390272031678)
 }
 
 func TestPredictRank(t *testing.T) {
@@ -327,3 +332,125 @@ func TestPredictRank(t *testing.T) {
 func almostEqual(a, b, epsilon float64) bool {
 	return math.Abs(a-b) < epsilon
 }
+
+func TestPredictWin100PercentForSolitare(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	teams := []types.Team{
+		{
+			test.PredictWinTeams["a1"],
+		},
+	}
+
+	probs := rating.PredictWin(teams, nil)
+	is.Equal(probs, []float64{1.0})
+}
+
+// simulate plays trials matches between teams by drawing the skill of every
+// player from N(mu, sigma^2) and adding N(0, beta^2) of performance noise to
+// every team, as the rating models do, and returns how often each team beat
+// each other team and how often each pair drew within drawMargin
+func simulate(teams []types.Team, weight [][]float64, beta, drawMargin float64, trials int) ([][]float64, [][]float64) {
+	r := rand.New(rand.NewPCG(1, 2))
+	n := len(teams)
+	wins := make([][]float64, n)
+	draws := make([][]float64, n)
+	for i := range wins {
+		wins[i] = make([]float64, n)
+		draws[i] = make([]float64, n)
+	}
+
+	performances := make([]float64, n)
+	for trial := 0; trial < trials; trial++ {
+		for i, team := range teams {
+			performances[i] = beta * r.NormFloat64()
+			for j, player := range team {
+				w := 1.0
+				if weight != nil {
+					w = weight[i][j]
+				}
+				performances[i] += w * (player.Mu + player.Sigma*r.NormFloat64())
+			}
+		}
+
+		for i := range teams {
+			for j := range teams {
+				if performances[i] > performances[j] {
+					wins[i][j]++
+				}
+				if math.Abs(performances[i]-performances[j]) < drawMargin {
+					draws[i][j]++
+				}
+			}
+		}
+	}
+
+	for i := range wins {
+		for j := range wins[i] {
+			wins[i][j] /= float64(trials)
+			draws[i][j] /= float64(trials)
+		}
+	}
+
+	return wins, draws
+}
+
+func TestPredictionsMatchMonteCarlo(t *testing.T) {
+	t.Parallel()
+
+	const trials = 200000
+	beta := 25 / 6.0
+	a1, a2 := test.PredictWinTeams["a1"], test.PredictWinTeams["a2"]
+	b1, b2 := test.PredictWinTeams["b1"], test.PredictWinTeams["b2"]
+
+	tests := []struct {
+		name   string
+		teams  []types.Team
+		weight [][]float64
+	}{
+		{name: "CloseDuel", teams: []types.Team{{a1}, {b2}}},
+		{name: "TwoTeams", teams: []types.Team{{a1, a2}, {b2, b2}}},
+		{name: "Asymmetric", teams: []types.Team{{a1, a2}, {b2}, {a2}, {a1, b2, a1}}},
+		{name: "Weighted", teams: []types.Team{{a1, a2}, {b1, b2}}, weight: [][]float64{{1, 0.5}, {0.25, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			is := _is.New(t)
+			options := &types.OpenSkillOptions{Weight: tt.weight}
+
+			n := float64(len(tt.teams))
+			players := 0
+			for _, team := range tt.teams {
+				players += len(team)
+			}
+			drawMargin := math.Sqrt(float64(players)) * beta * normalQuantile((1+1/n)/2)
+			wins, draws := simulate(tt.teams, tt.weight, beta, drawMargin, trials)
+
+			predicted := rating.PredictWin(tt.teams, options)
+			for i := range tt.teams {
+				expected := 0.0
+				for j := range tt.teams {
+					expected += wins[i][j]
+				}
+				expected /= n * (n - 1) / 2
+				is.True(almostEqual(predicted[i], expected, 0.005))
+			}
+
+			expected := 0.0
+			for i := range tt.teams {
+				for j := i + 1; j < len(tt.teams); j++ {
+					expected += draws[i][j]
+				}
+			}
+			expected /= n * (n - 1) / 2
+			is.True(almostEqual(rating.PredictDraw(tt.teams, options), expected, 0.005))
+		})
+	}
+}
+
+// normalQuantile returns the quantile of the standard normal distribution
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
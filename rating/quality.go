@@ -0,0 +1,74 @@
+package rating
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/types"
+	"github.com/intinig/go-openskill/util"
+)
+
+// MatchQuality returns how balanced a match is, between 0 and 1, in the way
+// TrueSkill defines it: the likelihood of a draw between all the teams,
+// relative to the likelihood of a draw between teams of perfectly known and
+// equal skill. It is high when the teams are close and the outcome uncertain,
+// and it shrinks as the mu of the teams drift apart or as their sigma grows.
+// Teams are aggregated like util.TeamRating does, weights included, and every
+// team adds beta squared of performance noise, like in PredictWin and the
+// rating models.
+func MatchQuality(teams []types.Team, options *types.OpenSkillOptions) float64 {
+	if len(teams) < 2 {
+		return 1.0
+	}
+
+	// Initialize util, used for teamRatings
+	_, betaSquared := getBetas(options)
+	u := util.NewWithOptions(&util.Options{
+		BetaSquared: ptr.Float64(betaSquared),
+	})
+
+	teamRatings := u.TeamRating(teams, options)
+	n := len(teamRatings)
+
+	// Each column of a compares the performances of two consecutive teams,
+	// any other set of independent comparisons gives the same result
+	a := mat.NewDense(n, n-1, nil)
+	for k := 0; k < n-1; k++ {
+		a.Set(k, k, 1)
+		a.Set(k+1, k, -1)
+	}
+
+	mu := mat.NewVecDense(n, nil)
+	noise := mat.NewDiagDense(n, nil)
+	skill := mat.NewDiagDense(n, nil)
+	for i, teamRating := range teamRatings {
+		mu.SetVec(i, teamRating.TeamMu)
+		noise.SetDiag(i, betaSquared)
+		skill.SetDiag(i, teamRating.TeamSigmaSquared)
+	}
+
+	// The variance of the comparisons, due to the performance noise alone and
+	// together with the uncertainty on the skill of the teams
+	var noiseVariance, skillVariance, total mat.Dense
+	noiseVariance.Product(a.T(), noise, a)
+	skillVariance.Product(a.T(), skill, a)
+	total.Add(&noiseVariance, &skillVariance)
+
+	// The expected outcome of the comparisons
+	var difference mat.VecDense
+	difference.MulVec(a.T(), mu)
+
+	var solved mat.VecDense
+	if err := solved.SolveVec(&total, &difference); err != nil {
+		return 0
+	}
+
+	// Determinants are taken in log-space, since they quickly overflow as the
+	// number of teams grows
+	logNoise, _ := mat.LogDet(&noiseVariance)
+	logTotal, _ := mat.LogDet(&total)
+
+	return math.Exp(-0.5*mat.Dot(&difference, &solved) + 0.5*(logNoise-logTotal))
+}
@@ -0,0 +1,119 @@
+package rating_test
+
+import (
+	"math"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+// duelQuality is the closed form of the match quality of two teams
+func duelQuality(a, b types.Team, beta float64) float64 {
+	muA, muB, sigmaA, sigmaB := 0.0, 0.0, 0.0, 0.0
+	for _, r := range a {
+		muA += r.Mu
+		sigmaA += r.Sigma * r.Sigma
+	}
+	for _, r := range b {
+		muB += r.Mu
+		sigmaB += r.Sigma * r.Sigma
+	}
+	noise := 2 * beta * beta
+	c := noise + sigmaA + sigmaB
+	return math.Sqrt(noise/c) * math.Exp(-(muA-muB)*(muA-muB)/(2*c))
+}
+
+func TestMatchQualityForNewPlayers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// Two new players, with sigma twice beta, give the classic sqrt(1/5)
+	q := rating.MatchQuality([]types.Team{{rating.New()}, {rating.New()}}, nil)
+	is.True(almostEqual(q, math.Sqrt(0.2), 1e-12))
+}
+
+func TestMatchQualityMatchesClosedFormForTwoTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	a := types.Team{test.Teams["a1"], test.Teams["b1"]}
+	b := types.Team{test.Teams["c1"], test.Teams["d1"], test.Teams["e1"]}
+	q := rating.MatchQuality([]types.Team{a, b}, nil)
+	is.True(almostEqual(q, duelQuality(a, b, 25/6.0), 1e-12))
+	is.Equal(q, rating.MatchQuality([]types.Team{b, a}, nil))
+}
+
+func TestMatchQualityUsesTheNoiseOfPredictions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	a := types.Team{test.Teams["a1"], test.Teams["b1"]}
+	b := types.Team{test.Teams["c1"], test.Teams["d1"], test.Teams["e1"]}
+	muA, muB := a[0].Mu+a[1].Mu, b[0].Mu+b[1].Mu+b[2].Mu
+
+	// PredictWin gives away the variance c of the difference in performance,
+	// which is all the match quality of two teams depends on
+	win := rating.PredictWin([]types.Team{a, b}, nil)[0]
+	c := math.Pow((muA-muB)/normalQuantile(win), 2)
+	beta := 25 / 6.0
+
+	q := rating.MatchQuality([]types.Team{a, b}, nil)
+	is.True(almostEqual(q, math.Sqrt(2*beta*beta/c)*math.Exp(-(muA-muB)*(muA-muB)/(2*c)), 1e-9))
+}
+
+func TestMatchQualityPrefersBalancedMatches(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rating.New()
+	strong := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(40)})
+	certain := rating.NewWithOptions(&types.OpenSkillOptions{Sigma: ptr.Float64(1)})
+
+	balanced := rating.MatchQuality([]types.Team{{r}, {r}}, nil)
+	is.True(rating.MatchQuality([]types.Team{{r}, {strong}}, nil) < balanced)
+	is.True(rating.MatchQuality([]types.Team{{certain}, {certain}}, nil) > balanced)
+	is.Equal(rating.MatchQuality([]types.Team{{r}}, nil), 1.0)
+}
+
+func TestMatchQualityForManyTeams(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	a, b, c := test.Teams["a1"], test.Teams["b1"], test.Teams["c1"]
+	q := rating.MatchQuality([]types.Team{{a}, {b}, {c}}, nil)
+	is.True(q > 0 && q < 1)
+
+	// The order of the teams does not matter
+	is.True(almostEqual(q, rating.MatchQuality([]types.Team{{c}, {a}, {b}}, nil), 1e-12))
+
+	// Adding an outsider makes the match worse
+	outsider := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(60), Sigma: ptr.Float64(2)})
+	is.True(rating.MatchQuality([]types.Team{{a}, {b}, {c}, {outsider}}, nil) < q)
+
+	// And many teams do not overflow
+	teams := make([]types.Team, 200)
+	for i := range teams {
+		teams[i] = types.Team{rating.New()}
+	}
+	q = rating.MatchQuality(teams, nil)
+	is.True(q > 0 && q < 1)
+}
+
+func TestMatchQualityUsesWeights(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	r := rating.New()
+	strong := rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(40)})
+	teams := []types.Team{{r, strong}, {r, r}}
+
+	// With the strong player barely playing, the teams are almost even
+	is.True(rating.MatchQuality(teams, &types.OpenSkillOptions{
+		Weight: [][]float64{{1, 0.1}, {1, 0.1}},
+	}) > rating.MatchQuality(teams, nil))
+}
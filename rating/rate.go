@@ -7,18 +7,38 @@ import (
 	"github.com/intinig/go-openskill/models"
 	"github.com/intinig/go-openskill/types"
 	"github.com/intinig/go-openskill/unwind"
+	"github.com/intinig/go-openskill/util"
 )
 
-// Rate takes an array of ratings and returns a new array of ratings based on their performance
+// Rate takes an array of ratings and returns a new array of ratings based on their performance.
+// Rate never modifies the options it is passed, so the same options can be
+// reused across matches and shared between goroutines.
 func Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
 	if options == nil {
 		options = &types.OpenSkillOptions{}
 	}
 
+	// Teams that did not take part are left out of the match altogether
+	if absent := absentTeams(options.Weight); absent != nil {
+		return rateWithout(teams, options, absent)
+	}
+
 	// Defaults to Plackett-Luce model
-	// TODO: implement all models
-	if options.Model == nil {
-		options.Model = models.NewPlackettLuce(options)
+	model := options.Model
+	if model == nil {
+		model = models.NewPlackettLuce(options)
+	}
+
+	// Players that have been away for a while come back with a larger sigma
+	if options.Decay != nil && !options.Now.IsZero() {
+		decayed := make([]types.Team, len(teams))
+		for i, team := range teams {
+			decayed[i] = make(types.Team, len(team))
+			for j, rating := range team {
+				decayed[i][j] = options.Decay.Decay(rating, options.Now)
+			}
+		}
+		teams = decayed
 	}
 
 	// Save for later
@@ -48,23 +68,40 @@ func Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
 	}
 
 	if options.Rank != nil {
-		// if options.Rank is provided, use it instead
-		rank = options.Rank
-	} else if options.Score != nil {
-		// if options.Score is provided, use it to calculate rank
-		for i := range options.Score {
-			rank[i] = -options.Score[i]
+		// if options.Rank is provided, use a copy of it instead, since we sort
+		// it later on
+		rank = append([]int(nil), options.Rank...)
+	} else if scores := util.Scores(options); scores != nil {
+		// if scores are provided, use them to calculate rank. In
+		// margin-of-victory mode scores that are close enough are a draw.
+		margin := 0.0
+		if options.Margin != nil {
+			margin = *options.Margin
 		}
+		rank = scoreRank(scores, margin, options.LowerIsBetter)
 	}
 
 	// Unwind teams and rank
 	teams, tenet := unwind.Teams(teams, rank)
 
+	// The model gets its own copy of the options, holding the rank and the
+	// weights in the same order as the unwound teams
+	modelOptions := *options
+	modelOptions.Model = model
+
 	// We re-sort the rank now that teams have been sorted
 	sort.Ints(rank)
-	options.Rank = rank
+	modelOptions.Rank = rank
+
+	// Weights have to follow the teams they belong to
+	modelOptions.Weight = unwind.Weights(options.Weight, tenet)
+
+	// And so do scores, which the models use in margin-of-victory mode
+	modelOptions.Score = unwind.Reorder(options.Score, tenet)
+	modelOptions.Scores = unwind.Reorder(options.Scores, tenet)
+
 	// Now we apply the new calculations
-	newRatings := options.Model.Rate(teams, options)
+	newRatings := model.Rate(teams, &modelOptions)
 
 	// Reverse the unwinding
 	teams, _ = unwind.Teams(newRatings, tenet)
@@ -81,5 +118,110 @@ func Rate(teams []types.Team, options *types.OpenSkillOptions) []types.Team {
 		}
 	}
 
+	// Models only deal with mu and sigma, so we carry over when the players
+	// last played, unless this match is more recent
+	for i, team := range teams {
+		for j := range team {
+			teams[i][j].LastPlayed = orig[i][j].LastPlayed
+			if !options.Now.IsZero() {
+				teams[i][j].LastPlayed = options.Now
+			}
+		}
+	}
+
 	return teams
 }
+
+// absentTeams reports which teams have all their players at a weight of 0,
+// it returns nil when every team took part
+func absentTeams(weight [][]float64) []bool {
+	var absent []bool
+	for i, team := range weight {
+		total := 0.0
+		for _, w := range team {
+			total += w
+		}
+		if total == 0 {
+			if absent == nil {
+				absent = make([]bool, len(weight))
+			}
+			absent[i] = true
+		}
+	}
+	return absent
+}
+
+// rateWithout rates the match between the teams that are not absent, as if
+// the absent ones were never there, and returns the absent ones unchanged
+func rateWithout(teams []types.Team, options *types.OpenSkillOptions, absent []bool) []types.Team {
+	present := *options
+	present.Rank = without(options.Rank, absent)
+	present.Score = without(options.Score, absent)
+	present.Scores = without(options.Scores, absent)
+	present.Weight = without(options.Weight, absent)
+
+	var rated []types.Team
+	if playing := without(teams, absent); len(playing) > 0 {
+		rated = Rate(playing, &present)
+	}
+
+	returning := make([]types.Team, len(teams))
+	for i, team := range teams {
+		if absent[i] {
+			returning[i] = append(types.Team(nil), team...)
+			continue
+		}
+		returning[i], rated = rated[0], rated[1:]
+	}
+
+	return returning
+}
+
+// without returns the values whose index is not absent, or nil for nil
+// values
+func without[T any](values []T, absent []bool) []T {
+	if values == nil {
+		return nil
+	}
+
+	kept := make([]T, 0, len(values))
+	for i, v := range values {
+		if !absent[i] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// scoreRank turns scores into ranks, where every team whose score is within
+// margin of the best score of its group shares the rank of that group. With a
+// margin of 0 only equal scores share a rank.
+func scoreRank(scores []float64, margin float64, lowerIsBetter bool) []int {
+	// better reports how far score a is ahead of score b
+	better := func(a, b float64) float64 {
+		if lowerIsBetter {
+			return b - a
+		}
+		return a - b
+	}
+
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return better(scores[order[a]], scores[order[b]]) > 0
+	})
+
+	rank := make([]int, len(scores))
+	group, leader := 0, 0
+	for k, i := range order {
+		if k == 0 || better(scores[leader], scores[i]) > margin {
+			group = k
+			leader = i
+		}
+		rank[i] = group
+	}
+
+	return rank
+}
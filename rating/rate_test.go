@@ -1,19 +1,27 @@
 package rating_test
 
 import (
+	"math"
+	"sync"
 	"testing"
+	"time"
 
 	_is "github.com/matryer/is"
 
+	"github.com/intinig/go-openskill/decay"
+	"github.com/intinig/go-openskill/models"
 	"github.com/intinig/go-openskill/ptr"
 	"github.com/intinig/go-openskill/rating"
 	"github.com/intinig/go-openskill/test"
 	"github.com/intinig/go-openskill/types"
 )
 
+// assertMuAndSigma checks mu and sigma up to rounding, so that reordering the
+// floating point operations of a model does not break the fixtures
 func assertMuAndSigma(is *_is.I, team types.Rating, mu, sigma float64) {
-	is.Equal(team.Mu, mu)
-	is.Equal(team.Sigma, sigma)
+	is.Helper()
+	is.True(math.Abs(team.Mu-mu) < 1e-12)       // mu differs by more than rounding
+	is.True(math.Abs(team.Sigma-sigma) < 1e-12) // sigma differs by more than rounding
 }
 
 func TestRateAcceptsAndRunsAPlacketLuceModelByDefault(t *testing.T) {
@@ -81,12 +89,10 @@ func TestAcceptsAMisorderedRankOrdering(t *testing.T) {
 			Rank: []int{2, 1, 4, 3},
 		},
 	)
-	is.Equal(teams, []types.Team{
-		{types.Rating{Mu: 26.552824984374855, Sigma: 8.179213704945203, Z: 3.0}},
-		{types.Rating{Mu: 27.795084971874736, Sigma: 8.263160757613477, Z: 3.0}},
-		{types.Rating{Mu: 20.96265504062538, Sigma: 8.083731307186588, Z: 3.0}},
-		{types.Rating{Mu: 24.68943500312503, Sigma: 8.083731307186588, Z: 3.0}},
-	})
+	assertMuAndSigma(is, teams[0][0], 26.552824984374855, 8.179213704945203)
+	assertMuAndSigma(is, teams[1][0], 27.795084971874736, 8.263160757613477)
+	assertMuAndSigma(is, teams[2][0], 20.96265504062538, 8.083731307186588)
+	assertMuAndSigma(is, teams[3][0], 24.68943500312503, 8.083731307186588)
 }
 
 func TestAcceptsTeamsInRatingOrder(t *testing.T) {
@@ -167,6 +173,28 @@ func TestAllowsTiesWithReorder(t *testing.T) {
 	is.Equal(res1[2], res2[0])
 }
 
+func TestRatesPartialPairingModelsInRankOrder(t *testing.T) {
+	t.Parallel()
+	a := test.Teams["a1"]
+	b := test.Teams["b1"]
+	c := test.Teams["c1"]
+	model := models.NewBradleyTerryPart(nil)
+
+	res1 := rating.Rate([]types.Team{{b}, {c}, {a}}, &types.OpenSkillOptions{
+		Model: model,
+		Rank:  []int{2, 3, 1},
+	})
+
+	res2 := rating.Rate([]types.Team{{a}, {b}, {c}}, &types.OpenSkillOptions{
+		Model: model,
+	})
+
+	is := _is.New(t)
+	is.Equal(res1[0], res2[1])
+	is.Equal(res1[1], res2[2])
+	is.Equal(res1[2], res2[0])
+}
+
 func TestFourWayTieWithNewbies(t *testing.T) {
 	t.Parallel()
 	is := _is.New(t)
@@ -203,7 +231,34 @@ func TestFixesOrdersOfTies(t *testing.T) {
 
 func TestRunsAModelWithTiesForFirst(t *testing.T) {
 	t.Parallel()
-	t.Skip("ThurstoneMostellerFull not implemented yet")
+	a := rating.NewWithOptions(
+		&types.OpenSkillOptions{
+			Mu:    ptr.Float64(10),
+			Sigma: ptr.Float64(8),
+		})
+	b := rating.NewWithOptions(
+		&types.OpenSkillOptions{
+			Mu:    ptr.Float64(5),
+			Sigma: ptr.Float64(10),
+		})
+	c := rating.NewWithOptions(
+		&types.OpenSkillOptions{
+			Mu:    ptr.Float64(0),
+			Sigma: ptr.Float64(12),
+		})
+
+	model := models.NewThurstoneMostellerFull(&types.OpenSkillOptions{
+		DrawMargin: ptr.Float64(0.1),
+	})
+	teams := rating.Rate([]types.Team{{a}, {b}, {c}}, &types.OpenSkillOptions{
+		Model: model,
+		Rank:  []int{1, 1, 2},
+	})
+
+	is := _is.New(t)
+	assertMuAndSigma(is, teams[0][0], 10.20579952169069, 6.940836786836724)
+	assertMuAndSigma(is, teams[1][0], 11.235146532180249, 7.218197815317841)
+	assertMuAndSigma(is, teams[2][0], -9.441659930143613, 9.071577135657254)
 }
 
 func TestAcceptsAScoreInsteadOfARank(t *testing.T) {
@@ -224,7 +279,322 @@ func TestAcceptsAScoreInsteadOfARank(t *testing.T) {
 
 func TestAcceptsWeightsForPartialPlay(t *testing.T) {
 	t.Parallel()
-	t.Skip("Needs a model that cares about partial scores")
+	teams := rating.Rate([]types.Team{
+		{test.Teams["a1"], test.Teams["d1"]},
+		{test.Teams["b1"], test.Teams["e1"]},
+	}, &types.OpenSkillOptions{
+		Weight: [][]float64{{1, 0.5}, {1, 1}},
+	})
+
+	is := _is.New(t)
+	assertMuAndSigma(is, teams[0][0], 30.40005716575848, 4.7481041385202625)
+	assertMuAndSigma(is, teams[0][1], 26.849507257053112, 8.288526790448007)
+	assertMuAndSigma(is, teams[1][0], 25.883578036762227, 4.865475645081168)
+	assertMuAndSigma(is, teams[1][1], 21.300985485893776, 8.055966526854577)
+}
+
+func TestFullWeightsMatchUnweightedRating(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{
+		{test.Teams["a1"], test.Teams["d1"]},
+		{test.Teams["b1"], test.Teams["e1"]},
+		{test.Teams["c1"]},
+	}
+
+	weighted := rating.Rate(teams, &types.OpenSkillOptions{
+		Weight: [][]float64{{1, 1}, {1, 1}, {1}},
+	})
+	unweighted := rating.Rate(teams, nil)
+
+	is := _is.New(t)
+	is.Equal(weighted, unweighted)
+}
+
+func TestZeroWeightLeavesPlayerUnchanged(t *testing.T) {
+	t.Parallel()
+	teams := rating.Rate([]types.Team{
+		{test.Teams["a1"], test.Teams["d1"]},
+		{test.Teams["b1"], test.Teams["e1"]},
+	}, &types.OpenSkillOptions{
+		Model:  models.NewBradleyTerryFull(nil),
+		Weight: [][]float64{{1, 0}, {1, 1}},
+	})
+
+	is := _is.New(t)
+	is.Equal(teams[0][1], test.Teams["d1"])
+}
+
+func TestAllZeroWeightsLeaveTeamUnchanged(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	a, b, c := test.Teams["a1"], test.Teams["b1"], test.Teams["c1"]
+	for name, model := range map[string]types.RatingModel{
+		"PlackettLuce":           models.NewPlackettLuce(nil),
+		"BradleyTerryFull":       models.NewBradleyTerryFull(nil),
+		"BradleyTerryPart":       models.NewBradleyTerryPart(nil),
+		"ThurstoneMostellerFull": models.NewThurstoneMostellerFull(nil),
+		"ThurstoneMostellerPart": models.NewThurstoneMostellerPart(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			// c sat out, so the match is rated as if it was only a against b
+			teams, err := rating.RateE([]types.Team{{a}, {c, c}, {b}}, &types.OpenSkillOptions{
+				Model:  model,
+				Rank:   []int{3, 1, 2},
+				Weight: [][]float64{{1}, {0, 0}, {1}},
+			})
+			is.NoErr(err)
+
+			expected := rating.Rate([]types.Team{{a}, {b}}, &types.OpenSkillOptions{
+				Model: model,
+				Rank:  []int{3, 2},
+			})
+			is.Equal(teams, []types.Team{expected[0], {c, c}, expected[1]})
+		})
+	}
+
+	// Nothing happens when nobody took part
+	teams := rating.Rate([]types.Team{{a}, {b}}, &types.OpenSkillOptions{Weight: [][]float64{{0}, {0}}})
+	is.Equal(teams, []types.Team{{a}, {b}})
+}
+
+func TestWeightsFollowReorderedTeams(t *testing.T) {
+	t.Parallel()
+	a := test.Teams["a1"]
+	b := test.Teams["b1"]
+	d := test.Teams["d1"]
+
+	res1 := rating.Rate([]types.Team{{b, d}, {a, d}}, &types.OpenSkillOptions{
+		Rank:   []int{2, 1},
+		Weight: [][]float64{{1, 0.25}, {0.5, 1}},
+	})
+
+	res2 := rating.Rate([]types.Team{{a, d}, {b, d}}, &types.OpenSkillOptions{
+		Rank:   []int{1, 2},
+		Weight: [][]float64{{0.5, 1}, {1, 0.25}},
+	})
+
+	is := _is.New(t)
+	is.Equal(res1[0], res2[1])
+	is.Equal(res1[1], res2[0])
+}
+
+func TestMarginTreatsCloseScoresAsDraws(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	is := _is.New(t)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Score:  []int{10, 9, 7},
+		Margin: ptr.Float64(2),
+	}), rating.Rate(teams, &types.OpenSkillOptions{
+		Rank: []int{0, 0, 1},
+	}))
+}
+
+func TestMarginGroupsScoresAroundTheBestOne(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	// 8 is within the margin of 9 but not of 10, so it does not draw with 10
+	is := _is.New(t)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Score:  []int{10, 9, 8},
+		Margin: ptr.Float64(1),
+	}), rating.Rate(teams, &types.OpenSkillOptions{
+		Rank: []int{0, 0, 1},
+	}))
+}
+
+func TestMarginIsDisabledByDefault(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+
+	is := _is.New(t)
+	expected := rating.Rate(teams, &types.OpenSkillOptions{Rank: []int{0, 1}})
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{Score: []int{10, 0}}), expected)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Score:  []int{10, 0},
+		Margin: ptr.Float64(0),
+	}), expected)
+}
+
+func TestMarginScalesMuUpdates(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+
+	for name, model := range map[string]types.RatingModel{
+		"PlackettLuce":           models.NewPlackettLuce(nil),
+		"BradleyTerryFull":       models.NewBradleyTerryFull(nil),
+		"BradleyTerryPart":       models.NewBradleyTerryPart(nil),
+		"ThurstoneMostellerFull": models.NewThurstoneMostellerFull(nil),
+		"ThurstoneMostellerPart": models.NewThurstoneMostellerPart(nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			is := _is.New(t)
+
+			plain := rating.Rate(teams, &types.OpenSkillOptions{Model: model, Rank: []int{0, 1}})
+			rate := func(score []int) []types.Team {
+				return rating.Rate(teams, &types.OpenSkillOptions{
+					Model:  model,
+					Score:  score,
+					Margin: ptr.Float64(1),
+				})
+			}
+			narrow := rate([]int{3, 1})
+			wide := rate([]int{20, 0})
+
+			gain := func(r []types.Team) float64 { return r[0][0].Mu - teams[0][0].Mu }
+			loss := func(r []types.Team) float64 { return teams[1][0].Mu - r[1][0].Mu }
+			is.True(gain(wide) > gain(narrow))
+			is.True(gain(narrow) > gain(plain))
+			is.True(loss(wide) > loss(narrow))
+			is.True(loss(narrow) > loss(plain))
+
+			// Only mu is scaled, sigma shrinks as it does for any win
+			for _, r := range []types.Team{narrow[0], wide[0]} {
+				is.Equal(r[0].Sigma, plain[0][0].Sigma)
+			}
+			for _, r := range []types.Team{narrow[1], wide[1]} {
+				is.Equal(r[0].Sigma, plain[1][0].Sigma)
+			}
+		})
+	}
+}
+
+func TestMarginScoresFollowReorderedTeams(t *testing.T) {
+	t.Parallel()
+	a, b, c := test.Teams["a1"], test.Teams["b1"], test.Teams["c1"]
+
+	result := rating.Rate([]types.Team{{a}, {b}, {c}}, &types.OpenSkillOptions{
+		Score:  []int{0, 5, 20},
+		Margin: ptr.Float64(2),
+	})
+	reversed := rating.Rate([]types.Team{{c}, {b}, {a}}, &types.OpenSkillOptions{
+		Score:  []int{20, 5, 0},
+		Margin: ptr.Float64(2),
+	})
+
+	is := _is.New(t)
+	is.Equal(result, []types.Team{reversed[2], reversed[1], reversed[0]})
+}
+
+func TestFloatScoresMatchIntScores(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	is := _is.New(t)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Scores: []float64{2.5, 7.25, 2.5},
+	}), rating.Rate(teams, &types.OpenSkillOptions{
+		Score: []int{2, 7, 2},
+	}))
+}
+
+func TestFloatScoresTakePrecedenceOverScore(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+
+	is := _is.New(t)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Score:  []int{10, 0},
+		Scores: []float64{0.5, 1.5},
+	}), rating.Rate(teams, &types.OpenSkillOptions{
+		Rank: []int{1, 0},
+	}))
+}
+
+func TestLowerIsBetterRanksLowestScoreFirst(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	is := _is.New(t)
+	expected := rating.Rate(teams, &types.OpenSkillOptions{
+		Rank: []int{2, 0, 1},
+	})
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Scores:        []float64{92.5, 88.125, 90},
+		LowerIsBetter: true,
+	}), expected)
+	is.Equal(rating.Rate(teams, &types.OpenSkillOptions{
+		Score:         []int{3, 1, 2},
+		LowerIsBetter: true,
+	}), expected)
+}
+
+func TestLowerIsBetterWorksWithMargin(t *testing.T) {
+	t.Parallel()
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+
+	// Lap times where the first two finish within the margin of each other,
+	// which must be rated like the same race with negated times
+	times := rating.Rate(teams, &types.OpenSkillOptions{
+		Scores:        []float64{60.25, 60, 65},
+		LowerIsBetter: true,
+		Margin:        ptr.Float64(0.5),
+	})
+
+	is := _is.New(t)
+	is.Equal(times, rating.Rate(teams, &types.OpenSkillOptions{
+		Scores: []float64{-60.25, -60, -65},
+		Margin: ptr.Float64(0.5),
+	}))
+	is.True(times[2][0].Mu < teams[2][0].Mu)
+}
+
+func TestRateStampsLastPlayed(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+
+	is := _is.New(t)
+	rated := rating.Rate(teams, &types.OpenSkillOptions{Now: now})
+	is.Equal(rated[0][0].LastPlayed, now)
+	is.Equal(rated[1][0].LastPlayed, now)
+
+	// Without Now the previous timestamps are carried over
+	again := rating.Rate(rated, nil)
+	is.Equal(again[0][0].LastPlayed, now)
+	is.Equal(again[1][0].LastPlayed, now)
+}
+
+func TestRateAppliesDecayBeforeRating(t *testing.T) {
+	t.Parallel()
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := then.Add(90 * 24 * time.Hour)
+	d := decay.New(nil, decay.Exponential(30*24*time.Hour))
+
+	a, b := test.Teams["a1"], test.Teams["b1"]
+	a.LastPlayed = then
+
+	rated := rating.Rate([]types.Team{{a}, {b}}, &types.OpenSkillOptions{
+		Now:   now,
+		Decay: d,
+	})
+	expected := rating.Rate([]types.Team{{d.Decay(a, now)}, {b}}, &types.OpenSkillOptions{
+		Now: now,
+	})
+
+	is := _is.New(t)
+	is.Equal(rated, expected)
+	is.True(rated[0][0].Sigma > rating.Rate([]types.Team{{a}, {b}}, nil)[0][0].Sigma)
+}
+
+func TestOrdinalAtAppliesDecay(t *testing.T) {
+	t.Parallel()
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := decay.New(nil, decay.Linear(1, 24*time.Hour))
+
+	r := test.Teams["a1"]
+	r.LastPlayed = then
+
+	is := _is.New(t)
+	is.Equal(rating.OrdinalAt(r, d, then), rating.Ordinal(r))
+	is.Equal(rating.OrdinalAt(r, nil, then.Add(time.Hour)), rating.Ordinal(r))
+	is.True(rating.OrdinalAt(r, d, then.Add(30*24*time.Hour)) < rating.Ordinal(r))
 }
 
 func TestAcceptsATauTerm(t *testing.T) {
@@ -270,3 +640,69 @@ func TestPreventsSigmaFromRising(t *testing.T) {
 	assertMuAndSigma(is, teams[0][0], 40.00032667136128, 3)
 	assertMuAndSigma(is, teams[1][0], -20.000326671361275, 3)
 }
+
+func TestRateDoesNotMutateOptions(t *testing.T) {
+	t.Parallel()
+	options := &types.OpenSkillOptions{
+		Rank:   []int{3, 1, 2},
+		Score:  []int{1, 3, 2},
+		Weight: [][]float64{{1}, {0.5}, {0.25}},
+		Tau:    ptr.Float64(0.3),
+	}
+
+	rating.Rate([]types.Team{
+		{test.Teams["a1"]},
+		{test.Teams["b1"]},
+		{test.Teams["c1"]},
+	}, options)
+
+	is := _is.New(t)
+	is.Equal(options.Rank, []int{3, 1, 2})
+	is.Equal(options.Score, []int{1, 3, 2})
+	is.Equal(options.Weight, [][]float64{{1}, {0.5}, {0.25}})
+	is.Equal(*options.Tau, 0.3)
+	is.True(options.Model == nil)
+}
+
+func TestRateCanReuseOptionsAcrossMatches(t *testing.T) {
+	t.Parallel()
+	options := &types.OpenSkillOptions{
+		Rank: []int{2, 1},
+	}
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}
+
+	first := rating.Rate(teams, options)
+	second := rating.Rate(teams, options)
+
+	is := _is.New(t)
+	is.Equal(first, second)
+	is.Equal(first, rating.Rate(teams, &types.OpenSkillOptions{
+		Rank: []int{2, 1},
+	}))
+}
+
+func TestRateCanShareOptionsBetweenGoroutines(t *testing.T) {
+	t.Parallel()
+	options := &types.OpenSkillOptions{
+		Rank:   []int{3, 1, 2},
+		Weight: [][]float64{{1}, {0.5}, {0.25}},
+	}
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+	expected := rating.Rate(teams, options)
+
+	results := make([][]types.Team, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = rating.Rate(teams, options)
+		}(i)
+	}
+	wg.Wait()
+
+	is := _is.New(t)
+	for _, result := range results {
+		is.Equal(result, expected)
+	}
+}
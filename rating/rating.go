@@ -1,7 +1,7 @@
 package rating
 
 import (
-	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/defaults"
 	"github.com/intinig/go-openskill/types"
 )
 
@@ -9,25 +9,12 @@ import (
 // mu is the mean of the rating distribution
 // sigma is the standard deviation of the rating distribution
 func NewWithOptions(options *types.OpenSkillOptions) types.Rating {
-	mu := options.Mu
-	if mu == nil {
-		mu = ptr.Float64(25.0)
-	}
-
-	z := options.Z
-	if z == nil {
-		z = ptr.Int(3)
-	}
-
-	sigma := options.Sigma
-	if sigma == nil {
-		sigma = ptr.Float64(*mu / float64(*z))
-	}
+	v := defaults.Resolve(options)
 
 	return types.Rating{
-		Mu:    *mu,
-		Sigma: *sigma,
-		Z:     *z,
+		Mu:    v.Mu,
+		Sigma: v.Sigma,
+		Z:     v.Z,
 	}
 }
 
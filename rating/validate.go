@@ -0,0 +1,221 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/intinig/go-openskill/types"
+)
+
+var (
+	// ErrNoTeams is returned when there are no teams to rate or predict
+	ErrNoTeams = errors.New("openskill: no teams")
+	// ErrEmptyTeam is returned when a team has no players
+	ErrEmptyTeam = errors.New("openskill: empty team")
+	// ErrRankLengthMismatch is returned when options.Rank does not have one
+	// entry per team
+	ErrRankLengthMismatch = errors.New("openskill: rank length does not match teams")
+	// ErrScoreLengthMismatch is returned when options.Score or options.Scores
+	// does not have one entry per team
+	ErrScoreLengthMismatch = errors.New("openskill: score length does not match teams")
+	// ErrInvalidScore is returned when a score in options.Scores is not finite
+	ErrInvalidScore = errors.New("openskill: invalid score")
+	// ErrWeightShapeMismatch is returned when options.Weight does not have one
+	// entry per player of each team
+	ErrWeightShapeMismatch = errors.New("openskill: weight shape does not match teams")
+	// ErrInvalidWeight is returned when a weight is negative or not a number
+	ErrInvalidWeight = errors.New("openskill: invalid weight")
+	// ErrInvalidMargin is returned when options.Margin is negative or not a
+	// number
+	ErrInvalidMargin = errors.New("openskill: invalid margin")
+	// ErrInvalidOption is returned when a hyperparameter of the options is out
+	// of range: Sigma, Beta, Tau and Z must be positive, Epsilon, Kappa and
+	// DrawMargin must not be negative, and all of them must be finite
+	ErrInvalidOption = errors.New("openskill: invalid option")
+	// ErrInvalidMu is returned when a rating has a mu that is not finite
+	ErrInvalidMu = errors.New("openskill: invalid mu")
+	// ErrInvalidSigma is returned when a rating has a sigma that is not
+	// finite or not positive
+	ErrInvalidSigma = errors.New("openskill: invalid sigma")
+)
+
+// Validate checks that teams and options can be rated or predicted, it
+// returns one of the Err* errors of this package, wrapped with the details of
+// the offending team or player
+func Validate(teams []types.Team, options *types.OpenSkillOptions) error {
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	if len(teams) == 0 {
+		return ErrNoTeams
+	}
+
+	if options.Rank != nil && len(options.Rank) != len(teams) {
+		return fmt.Errorf("%w: got %d ranks for %d teams", ErrRankLengthMismatch, len(options.Rank), len(teams))
+	}
+
+	if options.Score != nil && len(options.Score) != len(teams) {
+		return fmt.Errorf("%w: got %d scores for %d teams", ErrScoreLengthMismatch, len(options.Score), len(teams))
+	}
+
+	if options.Scores != nil && len(options.Scores) != len(teams) {
+		return fmt.Errorf("%w: got %d scores for %d teams", ErrScoreLengthMismatch, len(options.Scores), len(teams))
+	}
+
+	for i, score := range options.Scores {
+		if math.IsNaN(score) || math.IsInf(score, 0) {
+			return fmt.Errorf("%w: team %d has score %v", ErrInvalidScore, i, score)
+		}
+	}
+
+	if options.Weight != nil && len(options.Weight) != len(teams) {
+		return fmt.Errorf("%w: got %d weights for %d teams", ErrWeightShapeMismatch, len(options.Weight), len(teams))
+	}
+
+	if options.Margin != nil && (!(*options.Margin >= 0) || math.IsInf(*options.Margin, 0)) {
+		return fmt.Errorf("%w: %v", ErrInvalidMargin, *options.Margin)
+	}
+
+	if err := validateOptions(options); err != nil {
+		return err
+	}
+
+	for i, team := range teams {
+		if len(team) == 0 {
+			return fmt.Errorf("%w: team %d", ErrEmptyTeam, i)
+		}
+
+		for j, rating := range team {
+			if math.IsNaN(rating.Mu) || math.IsInf(rating.Mu, 0) {
+				return fmt.Errorf("%w: team %d, player %d has mu %v", ErrInvalidMu, i, j, rating.Mu)
+			}
+
+			if !(rating.Sigma > 0) || math.IsInf(rating.Sigma, 0) {
+				return fmt.Errorf("%w: team %d, player %d has sigma %v", ErrInvalidSigma, i, j, rating.Sigma)
+			}
+		}
+
+		if options.Weight != nil {
+			if err := validateWeight(i, team, options.Weight[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOptions checks the hyperparameters of options
+func validateOptions(options *types.OpenSkillOptions) error {
+	if options.Z != nil && *options.Z <= 0 {
+		return fmt.Errorf("%w: z is %d", ErrInvalidOption, *options.Z)
+	}
+
+	if options.Mu != nil && (math.IsNaN(*options.Mu) || math.IsInf(*options.Mu, 0)) {
+		return fmt.Errorf("%w: mu is %v", ErrInvalidOption, *options.Mu)
+	}
+
+	positive := []struct {
+		name  string
+		value *float64
+	}{
+		{"sigma", options.Sigma},
+		{"beta", options.Beta},
+		{"tau", options.Tau},
+	}
+	for _, o := range positive {
+		if o.value != nil && (!(*o.value > 0) || math.IsInf(*o.value, 0)) {
+			return fmt.Errorf("%w: %s is %v", ErrInvalidOption, o.name, *o.value)
+		}
+	}
+
+	nonNegative := []struct {
+		name  string
+		value *float64
+	}{
+		{"epsilon", options.Epsilon},
+		{"kappa", options.Kappa},
+		{"draw margin", options.DrawMargin},
+	}
+	for _, o := range nonNegative {
+		if o.value != nil && (!(*o.value >= 0) || math.IsInf(*o.value, 0)) {
+			return fmt.Errorf("%w: %s is %v", ErrInvalidOption, o.name, *o.value)
+		}
+	}
+
+	return nil
+}
+
+// validateWeight checks the weights of the i-th team
+func validateWeight(i int, team types.Team, weight []float64) error {
+	if len(weight) != len(team) {
+		return fmt.Errorf("%w: got %d weights for %d players in team %d", ErrWeightShapeMismatch, len(weight), len(team), i)
+	}
+
+	for j, w := range weight {
+		if !(w >= 0) || math.IsInf(w, 0) {
+			return fmt.Errorf("%w: team %d, player %d has weight %v", ErrInvalidWeight, i, j, w)
+		}
+	}
+
+	return nil
+}
+
+// RateE is like Rate, but it validates teams and options first and returns an
+// error instead of panicking or returning meaningless ratings
+func RateE(teams []types.Team, options *types.OpenSkillOptions) ([]types.Team, error) {
+	if err := Validate(teams, options); err != nil {
+		return nil, err
+	}
+
+	return Rate(teams, options), nil
+}
+
+// PredictWinE is like PredictWin, but it validates teams and options first
+func PredictWinE(teams []types.Team, options *types.OpenSkillOptions) ([]float64, error) {
+	if err := Validate(teams, options); err != nil {
+		return nil, err
+	}
+
+	return PredictWin(teams, options), nil
+}
+
+// PredictDrawE is like PredictDraw, but it validates teams and options first
+func PredictDrawE(teams []types.Team, options *types.OpenSkillOptions) (float64, error) {
+	if err := Validate(teams, options); err != nil {
+		return 0, err
+	}
+
+	return PredictDraw(teams, options), nil
+}
+
+// PredictRankE is like PredictRank, but it validates teams and options first
+func PredictRankE(teams []types.Team, options *types.OpenSkillOptions) ([]int64, []float64, error) {
+	if err := Validate(teams, options); err != nil {
+		return nil, nil, err
+	}
+
+	ranks, probabilities := PredictRank(teams, options)
+	return ranks, probabilities, nil
+}
+
+// PredictRankDistributionE is like PredictRankDistribution, but it validates
+// teams and options first
+func PredictRankDistributionE(teams []types.Team, options *types.OpenSkillOptions, simulation *SimulationOptions) (RankDistribution, error) {
+	if err := Validate(teams, options); err != nil {
+		return RankDistribution{}, err
+	}
+
+	return PredictRankDistribution(teams, options, simulation), nil
+}
+
+// MatchQualityE is like MatchQuality, but it validates teams and options first
+func MatchQualityE(teams []types.Team, options *types.OpenSkillOptions) (float64, error) {
+	if err := Validate(teams, options); err != nil {
+		return 0, err
+	}
+
+	return MatchQuality(teams, options), nil
+}
@@ -0,0 +1,283 @@
+package rating_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	a := test.Teams["a1"]
+	b := test.Teams["b1"]
+
+	tests := []struct {
+		name     string
+		teams    []types.Team
+		options  *types.OpenSkillOptions
+		expected error
+	}{
+		{
+			name:     "Valid",
+			teams:    []types.Team{{a}, {b}},
+			options:  nil,
+			expected: nil,
+		},
+		{
+			name:  "ValidWithEverything",
+			teams: []types.Team{{a, b}, {b}},
+			options: &types.OpenSkillOptions{
+				Rank:   []int{2, 1},
+				Score:  []int{1, 2},
+				Weight: [][]float64{{1, 0}, {0.5}},
+			},
+			expected: nil,
+		},
+		{
+			name:     "NoTeams",
+			teams:    []types.Team{},
+			expected: rating.ErrNoTeams,
+		},
+		{
+			name:     "EmptyTeam",
+			teams:    []types.Team{{a}, {}},
+			expected: rating.ErrEmptyTeam,
+		},
+		{
+			name:     "RankLengthMismatch",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Rank: []int{1}},
+			expected: rating.ErrRankLengthMismatch,
+		},
+		{
+			name:     "ScoreLengthMismatch",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Score: []int{1, 2, 3}},
+			expected: rating.ErrScoreLengthMismatch,
+		},
+		{
+			name:     "WeightTeamsMismatch",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Weight: [][]float64{{1}}},
+			expected: rating.ErrWeightShapeMismatch,
+		},
+		{
+			name:     "WeightPlayersMismatch",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Weight: [][]float64{{1}, {1, 1}}},
+			expected: rating.ErrWeightShapeMismatch,
+		},
+		{
+			name:     "NegativeWeight",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Weight: [][]float64{{1}, {-1}}},
+			expected: rating.ErrInvalidWeight,
+		},
+		{
+			name:     "AllZeroWeights",
+			teams:    []types.Team{{a, b}, {b}},
+			options:  &types.OpenSkillOptions{Weight: [][]float64{{0, 0}, {1}}},
+			expected: nil,
+		},
+		{
+			name:     "FloatScoreMismatch",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Scores: []float64{1.5}},
+			expected: rating.ErrScoreLengthMismatch,
+		},
+		{
+			name:     "NaNScore",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Scores: []float64{1.5, math.NaN()}},
+			expected: rating.ErrInvalidScore,
+		},
+		{
+			name:     "NegativeMargin",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Score: []int{1, 0}, Margin: ptr.Float64(-1)},
+			expected: rating.ErrInvalidMargin,
+		},
+		{
+			name:     "ZeroZ",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Z: ptr.Int(0)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NegativeZ",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Z: ptr.Int(-3)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "InfiniteMu",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Mu: ptr.Float64(math.Inf(1))},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "ZeroSigmaOption",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Sigma: ptr.Float64(0)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NaNSigmaOption",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Sigma: ptr.Float64(math.NaN())},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NegativeBeta",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Beta: ptr.Float64(-1)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "InfiniteBeta",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Beta: ptr.Float64(math.Inf(1))},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "ZeroTau",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Tau: ptr.Float64(0)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NaNTau",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Tau: ptr.Float64(math.NaN())},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NegativeEpsilon",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Epsilon: ptr.Float64(-0.1)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NaNKappa",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{Kappa: ptr.Float64(math.NaN())},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NegativeDrawMargin",
+			teams:    []types.Team{{a}, {b}},
+			options:  &types.OpenSkillOptions{DrawMargin: ptr.Float64(-1)},
+			expected: rating.ErrInvalidOption,
+		},
+		{
+			name:     "NaNMu",
+			teams:    []types.Team{{a}, {types.Rating{Mu: math.NaN(), Sigma: 1, Z: 3}}},
+			expected: rating.ErrInvalidMu,
+		},
+		{
+			name:     "ZeroSigma",
+			teams:    []types.Team{{a}, {types.Rating{Mu: 25, Sigma: 0, Z: 3}}},
+			expected: rating.ErrInvalidSigma,
+		},
+		{
+			name:     "NegativeSigma",
+			teams:    []types.Team{{types.Rating{Mu: 25, Sigma: -1, Z: 3}}, {b}},
+			expected: rating.ErrInvalidSigma,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			is := _is.New(t)
+			err := rating.Validate(tt.teams, tt.options)
+			if tt.expected == nil {
+				is.NoErr(err)
+				return
+			}
+			is.True(errors.Is(err, tt.expected))
+		})
+	}
+}
+
+func TestRateEReturnsErrorsInsteadOfPanicking(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams, err := rating.RateE([]types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}, &types.OpenSkillOptions{
+		Rank: []int{1, 2, 3},
+	})
+	is.True(errors.Is(err, rating.ErrRankLengthMismatch))
+	is.Equal(teams, nil)
+
+	// A zero beta would otherwise turn every rating into NaN
+	teams, err = rating.RateE([]types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}}, &types.OpenSkillOptions{
+		Beta: ptr.Float64(0),
+	})
+	is.True(errors.Is(err, rating.ErrInvalidOption))
+	is.Equal(teams, nil)
+}
+
+func TestRateEMatchesRate(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{{test.Teams["a1"]}, {test.Teams["b1"]}, {test.Teams["c1"]}}
+	options := &types.OpenSkillOptions{Rank: []int{2, 3, 1}}
+
+	rated, err := rating.RateE(teams, options)
+	is.NoErr(err)
+	is.Equal(rated, rating.Rate(teams, options))
+}
+
+func TestPredictEFunctions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{{test.PredictWinTeams["a1"]}, {test.PredictWinTeams["b1"]}}
+
+	win, err := rating.PredictWinE(teams, nil)
+	is.NoErr(err)
+	is.Equal(win, rating.PredictWin(teams, nil))
+
+	draw, err := rating.PredictDrawE(teams, nil)
+	is.NoErr(err)
+	is.Equal(draw, rating.PredictDraw(teams, nil))
+
+	ranks, probabilities, err := rating.PredictRankE(teams, nil)
+	is.NoErr(err)
+	expectedRanks, expectedProbabilities := rating.PredictRank(teams, nil)
+	is.Equal(ranks, expectedRanks)
+	is.Equal(probabilities, expectedProbabilities)
+
+	distribution, err := rating.PredictRankDistributionE(teams, nil, &rating.SimulationOptions{Samples: 10})
+	is.NoErr(err)
+	is.Equal(len(distribution.Probabilities), 2)
+
+	_, err = rating.PredictRankDistributionE(nil, nil, nil)
+	is.True(errors.Is(err, rating.ErrNoTeams))
+
+	quality, err := rating.MatchQualityE(teams, nil)
+	is.NoErr(err)
+	is.Equal(quality, rating.MatchQuality(teams, nil))
+
+	_, err = rating.MatchQualityE([]types.Team{{}, {}}, nil)
+	is.True(errors.Is(err, rating.ErrEmptyTeam))
+
+	_, err = rating.PredictWinE([]types.Team{{test.PredictWinTeams["a1"]}, {}}, nil)
+	is.True(errors.Is(err, rating.ErrEmptyTeam))
+
+	_, err = rating.PredictDrawE(nil, nil)
+	is.True(errors.Is(err, rating.ErrNoTeams))
+
+	_, _, err = rating.PredictRankE(teams, &types.OpenSkillOptions{Weight: [][]float64{{1}}})
+	is.True(errors.Is(err, rating.ErrWeightShapeMismatch))
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// DefaultShards is the number of shards used by NewSharded when it is not
+// given a positive count
+const DefaultShards = 32
+
+// ErrDuplicatePlayer is returned when the same player appears more than once
+// in a match
+var ErrDuplicatePlayer = errors.New("openskill: duplicate player")
+
+// RatingStore keeps ratings keyed by player ID
+type RatingStore interface {
+	// Get returns the rating of a player and whether it was found
+	Get(id string) (types.Rating, bool)
+	// Set stores the rating of a player
+	Set(id string, r types.Rating)
+	// Delete removes the rating of a player
+	Delete(id string)
+	// Rate atomically reads the ratings of the players in teams, rates them
+	// with rating.Rate and stores the results, which are also returned.
+	// Players that are not in the store yet start from a new rating built
+	// from options.
+	Rate(teams [][]string, options *types.OpenSkillOptions) ([]types.Team, error)
+}
+
+// shard is a portion of the store guarded by its own lock
+type shard struct {
+	sync.RWMutex
+	ratings map[string]types.Rating
+}
+
+// Sharded is an in-memory RatingStore that spreads players across shards, so
+// that matches that do not share a shard never wait on each other
+type Sharded struct {
+	shards []*shard
+}
+
+var _ RatingStore = (*Sharded)(nil)
+
+// NewSharded returns a new Sharded store with count shards
+func NewSharded(count int) *Sharded {
+	if count <= 0 {
+		count = DefaultShards
+	}
+
+	shards := make([]*shard, count)
+	for i := range shards {
+		shards[i] = &shard{
+			ratings: make(map[string]types.Rating),
+		}
+	}
+
+	return &Sharded{
+		shards: shards,
+	}
+}
+
+// shardIndex returns the index of the shard holding id
+func (s *Sharded) shardIndex(id string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(id))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// Get returns the rating of a player and whether it was found
+func (s *Sharded) Get(id string) (types.Rating, bool) {
+	sh := s.shards[s.shardIndex(id)]
+	sh.RLock()
+	defer sh.RUnlock()
+
+	r, ok := sh.ratings[id]
+	return r, ok
+}
+
+// Set stores the rating of a player
+func (s *Sharded) Set(id string, r types.Rating) {
+	sh := s.shards[s.shardIndex(id)]
+	sh.Lock()
+	defer sh.Unlock()
+
+	sh.ratings[id] = r
+}
+
+// Delete removes the rating of a player
+func (s *Sharded) Delete(id string) {
+	sh := s.shards[s.shardIndex(id)]
+	sh.Lock()
+	defer sh.Unlock()
+
+	delete(sh.ratings, id)
+}
+
+// Len returns the number of players in the store
+func (s *Sharded) Len() int {
+	n := 0
+	for _, sh := range s.shards {
+		sh.RLock()
+		n += len(sh.ratings)
+		sh.RUnlock()
+	}
+	return n
+}
+
+// Rate atomically reads the ratings of the players in teams, rates them with
+// rating.Rate and stores the results, which are also returned. Players that
+// are not in the store yet start from a new rating built from options.
+func (s *Sharded) Rate(teams [][]string, options *types.OpenSkillOptions) ([]types.Team, error) {
+	if options == nil {
+		options = &types.OpenSkillOptions{}
+	}
+
+	// Find every shard involved in the match, players can only appear once
+	seen := make(map[string]bool)
+	involved := make(map[int]bool)
+	for _, team := range teams {
+		for _, id := range team {
+			if seen[id] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicatePlayer, id)
+			}
+			seen[id] = true
+			involved[s.shardIndex(id)] = true
+		}
+	}
+
+	// Shards are always locked in ascending order, so that two matches
+	// sharing more than one shard can never deadlock
+	indexes := make([]int, 0, len(involved))
+	for index := range involved {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		s.shards[index].Lock()
+	}
+	defer func() {
+		for _, index := range indexes {
+			s.shards[index].Unlock()
+		}
+	}()
+
+	ratings := make([]types.Team, len(teams))
+	for i, team := range teams {
+		ratings[i] = make(types.Team, len(team))
+		for j, id := range team {
+			r, ok := s.shards[s.shardIndex(id)].ratings[id]
+			if !ok {
+				r = rating.NewWithOptions(options)
+			}
+			ratings[i][j] = r
+		}
+	}
+
+	rated, err := rating.RateE(ratings, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, team := range teams {
+		for j, id := range team {
+			s.shards[s.shardIndex(id)].ratings[id] = rated[i][j]
+		}
+	}
+
+	return rated, nil
+}
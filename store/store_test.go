@@ -0,0 +1,155 @@
+package store_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/store"
+	"github.com/intinig/go-openskill/test"
+	"github.com/intinig/go-openskill/types"
+)
+
+// incrementModel is a RatingModel that adds 1 to the mu of every player, it
+// makes lost updates easy to spot
+type incrementModel struct{}
+
+func (incrementModel) Rate(teams []types.Team, _ *types.OpenSkillOptions) []types.Team {
+	returning := make([]types.Team, len(teams))
+	for i, team := range teams {
+		returning[i] = make(types.Team, len(team))
+		for j, r := range team {
+			returning[i][j] = types.Rating{Mu: r.Mu + 1, Sigma: r.Sigma, Z: r.Z}
+		}
+	}
+	return returning
+}
+
+func TestShardedGetSetDelete(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	s := store.NewSharded(4)
+	_, ok := s.Get("a")
+	is.True(!ok)
+
+	s.Set("a", test.Teams["a1"])
+	r, ok := s.Get("a")
+	is.True(ok)
+	is.Equal(r, test.Teams["a1"])
+	is.Equal(s.Len(), 1)
+
+	s.Delete("a")
+	_, ok = s.Get("a")
+	is.True(!ok)
+	is.Equal(s.Len(), 0)
+}
+
+func TestShardedRateMatchesRate(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	s := store.NewSharded(0)
+	s.Set("a", test.Teams["a1"])
+	s.Set("b", test.Teams["b1"])
+	s.Set("c", test.Teams["c1"])
+
+	options := &types.OpenSkillOptions{Rank: []int{2, 1, 3}}
+	rated, err := s.Rate([][]string{{"a"}, {"b", "d"}, {"c"}}, options)
+	is.NoErr(err)
+
+	expected := rating.Rate([]types.Team{
+		{test.Teams["a1"]},
+		{test.Teams["b1"], rating.New()},
+		{test.Teams["c1"]},
+	}, options)
+	is.Equal(rated, expected)
+
+	for id, r := range map[string]types.Rating{
+		"a": expected[0][0],
+		"b": expected[1][0],
+		"d": expected[1][1],
+		"c": expected[2][0],
+	} {
+		stored, ok := s.Get(id)
+		is.True(ok)
+		is.Equal(stored, r)
+	}
+}
+
+func TestShardedRateUsesOptionsForNewPlayers(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	s := store.NewSharded(4)
+	_, err := s.Rate([][]string{{"a"}, {"b"}}, &types.OpenSkillOptions{
+		Mu:    ptr.Float64(1500),
+		Sigma: ptr.Float64(350),
+		Model: incrementModel{},
+	})
+	is.NoErr(err)
+
+	r, _ := s.Get("a")
+	is.Equal(r, types.Rating{Mu: 1501, Sigma: 350, Z: 3})
+}
+
+func TestShardedRateRejectsInvalidMatches(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	s := store.NewSharded(4)
+	s.Set("a", test.Teams["a1"])
+
+	_, err := s.Rate([][]string{{"a"}, {"a"}}, nil)
+	is.True(errors.Is(err, store.ErrDuplicatePlayer))
+
+	_, err = s.Rate([][]string{{"a"}, {"b"}}, &types.OpenSkillOptions{Rank: []int{1}})
+	is.True(errors.Is(err, rating.ErrRankLengthMismatch))
+
+	// Nothing is written when a match is rejected
+	r, _ := s.Get("a")
+	is.Equal(r, test.Teams["a1"])
+	is.Equal(s.Len(), 1)
+}
+
+func TestShardedRateDoesNotLoseConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	s := store.NewSharded(8)
+	options := &types.OpenSkillOptions{Model: incrementModel{}}
+
+	const matches = 200
+	var wg sync.WaitGroup
+	for i := 0; i < matches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every match shares "hub" and one of a few other players, so
+			// that matches overlap on more than one shard
+			_, err := s.Rate([][]string{
+				{"hub", fmt.Sprintf("p%d", i%5)},
+				{fmt.Sprintf("q%d", i%7)},
+			}, options)
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	hub, _ := s.Get("hub")
+	is.Equal(hub.Mu, 25.0+matches)
+
+	total := 0.0
+	for i := 0; i < 5; i++ {
+		r, _ := s.Get(fmt.Sprintf("p%d", i))
+		total += r.Mu - 25
+	}
+	is.Equal(total, float64(matches))
+}
@@ -0,0 +1,244 @@
+package tournament
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// SingleElimination is a knockout bracket where a player is out after their
+// first loss. Top seeds get byes when the number of players is not a power of
+// two.
+type SingleElimination struct{}
+
+func (SingleElimination) play(win [][]float64, r *rand.Rand) []int {
+	slots := bracket(len(win))
+	lasted := make([]int, len(win))
+
+	round := 0
+	for len(slots) > 1 {
+		round++
+		var losers []int
+		slots, losers = playRound(win, r, slots)
+		for _, loser := range losers {
+			if loser >= 0 {
+				lasted[loser] = round
+			}
+		}
+	}
+	lasted[slots[0]] = round + 1
+
+	return positions(lasted)
+}
+
+// DoubleElimination is a bracket where a player is out after their second
+// loss. Players who lose in the winners bracket drop to the losers bracket,
+// and the grand final is replayed when the winner of the losers bracket wins
+// it.
+type DoubleElimination struct{}
+
+func (DoubleElimination) play(win [][]float64, r *rand.Rand) []int {
+	lasted := make([]int, len(win))
+	stage := 0
+	out := func(losers []int) {
+		stage++
+		for _, loser := range losers {
+			if loser >= 0 {
+				lasted[loser] = stage
+			}
+		}
+	}
+
+	winners, dropped := playRound(win, r, bracket(len(win)))
+	losers := dropped
+	if len(losers) > 1 {
+		var eliminated []int
+		losers, eliminated = playRound(win, r, losers)
+		out(eliminated)
+	}
+
+	for len(winners) > 1 {
+		winners, dropped = playRound(win, r, winners)
+
+		// Players dropping from the winners bracket meet the survivors of
+		// the losers bracket, in reverse order to delay rematches
+		pairs := make([]int, 0, 2*len(losers))
+		for k := range losers {
+			pairs = append(pairs, losers[k], dropped[len(dropped)-1-k])
+		}
+		var eliminated []int
+		losers, eliminated = playRound(win, r, pairs)
+		out(eliminated)
+
+		if len(losers) > 1 {
+			losers, eliminated = playRound(win, r, losers)
+			out(eliminated)
+		}
+	}
+
+	// The winner of the losers bracket has to win the grand final twice
+	champion, finalist := game(win, r, winners[0], losers[0])
+	if champion == losers[0] {
+		champion, finalist = game(win, r, champion, finalist)
+	}
+	out([]int{finalist})
+	lasted[champion] = stage + 1
+
+	return positions(lasted)
+}
+
+// RoundRobin is an event where every player plays every other player once,
+// ties on points are broken at random
+type RoundRobin struct{}
+
+func (RoundRobin) play(win [][]float64, r *rand.Rand) []int {
+	points := make([]int, len(win))
+	for i := range win {
+		for j := i + 1; j < len(win); j++ {
+			winner, _ := game(win, r, i, j)
+			points[winner]++
+		}
+	}
+
+	return standings(points, r)
+}
+
+// Swiss is an event of a fixed number of rounds, where every round players
+// meet an opponent with the same number of points they have not met yet, the
+// top half of each group by seed against the bottom half. When there is an
+// odd number of players, the lowest ranked player who has not had
+// a bye yet gets one, which counts as a win. Ties on points are broken at
+// random.
+type Swiss struct {
+	// Rounds is the number of rounds, the default value is the number of
+	// rounds of a single elimination bracket with the same players
+	Rounds int
+}
+
+func (s Swiss) play(win [][]float64, r *rand.Rand) []int {
+	n := len(win)
+	rounds := s.Rounds
+	if rounds <= 0 {
+		rounds = int(math.Ceil(math.Log2(float64(n))))
+	}
+
+	points := make([]int, n)
+	played := make([]map[int]bool, n)
+	for i := range played {
+		played[i] = make(map[int]bool)
+	}
+	hadBye := make([]bool, n)
+
+	for round := 0; round < rounds; round++ {
+		// Players are paired by points, then by seed
+		order := make([]int, n)
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return points[order[a]] > points[order[b]]
+		})
+
+		if n%2 == 1 {
+			for k := n - 1; k >= 0; k-- {
+				if i := order[k]; !hadBye[i] || k == 0 {
+					hadBye[i] = true
+					points[i]++
+					order = append(order[:k], order[k+1:]...)
+					break
+				}
+			}
+		}
+
+		// Players meet opponents on the same points, top half against bottom
+		// half, and players left unpaired float down to the next group
+		var pairs, floaters []int
+		for start := 0; start < len(order); {
+			end := start
+			for end < len(order) && points[order[end]] == points[order[start]] {
+				end++
+			}
+			group := append(append([]int(nil), floaters...), order[start:end]...)
+			var paired []int
+			paired, floaters = pairGroup(group, played)
+			pairs = append(pairs, paired...)
+			start = end
+		}
+
+		// Whoever is still unpaired meets the next player left, again if they
+		// have to
+		pairs = append(pairs, floaters...)
+
+		for k := 0; k < len(pairs); k += 2 {
+			i, j := pairs[k], pairs[k+1]
+			played[i][j], played[j][i] = true, true
+			winner, _ := game(win, r, i, j)
+			points[winner]++
+		}
+	}
+
+	return standings(points, r)
+}
+
+// pairGroup pairs the top half of group with its bottom half, each player
+// meeting the highest one of the other half they have not met yet. It returns
+// the pairs, as consecutive players, and the players left unpaired in order.
+func pairGroup(group []int, played []map[int]bool) ([]int, []int) {
+	half := len(group) / 2
+	paired := make([]bool, len(group))
+
+	var pairs []int
+	for a := 0; a < half; a++ {
+		for b := half; b < len(group); b++ {
+			if !paired[b] && !played[group[a]][group[b]] {
+				paired[a], paired[b] = true, true
+				pairs = append(pairs, group[a], group[b])
+				break
+			}
+		}
+	}
+
+	var unpaired []int
+	for k, i := range group {
+		if !paired[k] {
+			unpaired = append(unpaired, i)
+		}
+	}
+	return pairs, unpaired
+}
+
+// playRound plays the games between consecutive slots, and returns the
+// winners and the losers in order
+func playRound(win [][]float64, r *rand.Rand, slots []int) ([]int, []int) {
+	winners := make([]int, len(slots)/2)
+	losers := make([]int, len(slots)/2)
+	for k := range winners {
+		winners[k], losers[k] = game(win, r, slots[2*k], slots[2*k+1])
+	}
+	return winners, losers
+}
+
+// bracket returns the first round of a bracket for n players given in seed
+// order, padded with byes, represented by -1, up to a power of two. Seeds are
+// placed so that the top two can only meet in the final, the top four in the
+// semi-finals and so on.
+func bracket(n int) []int {
+	order := []int{0}
+	for len(order) < n {
+		size := 2 * len(order)
+		next := make([]int, 0, size)
+		for _, seed := range order {
+			next = append(next, seed, size-1-seed)
+		}
+		order = next
+	}
+
+	slots := make([]int, len(order))
+	for k, seed := range order {
+		slots[k] = seed
+		if seed >= n {
+			slots[k] = -1
+		}
+	}
+	return slots
+}
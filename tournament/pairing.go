@@ -0,0 +1,533 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+const (
+	// WinPoints is what a player scores for a win or a bye
+	WinPoints = 1.0
+	// DrawPoints is what a player scores for a draw
+	DrawPoints = 0.5
+)
+
+// pairingBudget is how many pairings PairSwiss tries before giving up on
+// avoiding rematches
+const pairingBudget = 100000
+
+// absolutePreference is the strength from which a colour preference must be
+// met, see preference
+const absolutePreference = 3
+
+var (
+	// ErrDuplicatePlayer is returned when two players share the same ID
+	ErrDuplicatePlayer = errors.New("openskill: duplicate player")
+	// ErrEmptyID is returned when a player has an empty ID, which Round uses
+	// to tell that nobody has a bye
+	ErrEmptyID = errors.New("openskill: empty player ID")
+	// ErrRoundInProgress is returned when pairing a round before the results
+	// of the previous one are in
+	ErrRoundInProgress = errors.New("openskill: round in progress")
+	// ErrNoRoundInProgress is returned when reporting results without a
+	// round being played
+	ErrNoRoundInProgress = errors.New("openskill: no round in progress")
+	// ErrResultsMismatch is returned when the results do not match the
+	// pairings of the round
+	ErrResultsMismatch = errors.New("openskill: results do not match pairings")
+)
+
+// Colour is the side a player takes in a game, White being the one that
+// moves first, serves first or plays at home
+type Colour int
+
+const (
+	White Colour = iota
+	Black
+)
+
+// Result is the outcome of a game
+type Result int
+
+const (
+	WhiteWins Result = iota
+	BlackWins
+	Draw
+)
+
+// Standing is where a player stands in a Swiss event
+type Standing struct {
+	// ID identifies the player, it cannot be empty
+	ID string
+	// Rating is the rating of the player, it breaks ties on points
+	Rating types.Rating
+	// Points is the score of the player so far, see WinPoints and DrawPoints
+	Points float64
+	// Opponents holds the IDs of the players already met, in order
+	Opponents []string
+	// Colours holds the colour the player had in each game, in order
+	Colours []Colour
+	// Byes is the number of byes the player had
+	Byes int
+}
+
+// Pairing is a game of a round
+type Pairing struct {
+	White string
+	Black string
+}
+
+// Round is the pairings of a round of a Swiss event
+type Round struct {
+	// Number is the number of the round, starting from 1
+	Number int
+	// Pairings holds the games of the round, from the top board down
+	Pairings []Pairing
+	// Bye is the ID of the player sitting the round out, empty when there is
+	// an even number of players
+	Bye string
+}
+
+// PairSwiss pairs the next round of a Swiss event. Players are ranked by
+// points, then by ordinal, and meet a player on the same points they have not
+// met yet, the top half of each group against the bottom half, floating down
+// to the next group when they cannot. Colours are given to even out the
+// number of games each player has with White, and to avoid the same colour
+// twice in a row. Two players who both had the same colour in their last two
+// games, or who both had it two more times than the other one, do not meet,
+// so that nobody gets it a third time. Those rules are only broken when there
+// is no other way to pair everyone, and rematches only happen when even that
+// is not enough. With an odd number of players, the lowest ranked player with
+// the fewest byes sits out.
+func PairSwiss(standings []Standing) (Round, error) {
+	if len(standings) < 2 {
+		return Round{}, fmt.Errorf("%w: got %d", ErrTooFewPlayers, len(standings))
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range standings {
+		if s.ID == "" {
+			return Round{}, ErrEmptyID
+		}
+		if seen[s.ID] {
+			return Round{}, fmt.Errorf("%w: %s", ErrDuplicatePlayer, s.ID)
+		}
+		seen[s.ID] = true
+	}
+
+	p := &pairer{
+		standings: standings,
+		order:     rank(standings),
+		met:       make([]map[string]bool, len(standings)),
+	}
+	for i, s := range standings {
+		p.met[i] = make(map[string]bool)
+		for _, id := range s.Opponents {
+			p.met[i][id] = true
+		}
+	}
+
+	pairs, bye, ok := p.pair(false, false)
+	if !ok {
+		pairs, bye, ok = p.pair(false, true)
+	}
+	if !ok {
+		pairs, bye, _ = p.pair(true, true)
+	}
+
+	round := Round{Pairings: make([]Pairing, len(pairs))}
+	for board, pair := range pairs {
+		white, black := p.colours(pair[0], pair[1], board)
+		round.Pairings[board] = Pairing{White: standings[white].ID, Black: standings[black].ID}
+	}
+	if bye >= 0 {
+		round.Bye = standings[bye].ID
+	}
+
+	return round, nil
+}
+
+// rank returns the indexes of standings by points, then by ordinal, then by
+// ID
+func rank(standings []Standing) []int {
+	order := make([]int, len(standings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := standings[order[a]], standings[order[b]]
+		if x.Points != y.Points {
+			return x.Points > y.Points
+		}
+		if ox, oy := rating.Ordinal(x.Rating), rating.Ordinal(y.Rating); ox != oy {
+			return ox > oy
+		}
+		return x.ID < y.ID
+	})
+	return order
+}
+
+// pairer holds the state of a call to PairSwiss
+type pairer struct {
+	standings     []Standing
+	order         []int
+	met           []map[string]bool
+	rematches     bool
+	colourClashes bool
+	budget        int
+}
+
+// pair returns the games of the round, as indexes of standings from the top
+// board down, and the index of the player with a bye or -1. It reports false
+// when it runs out of budget without avoiding rematches, or games between
+// players who must both get the same colour when colourClashes is false.
+func (p *pairer) pair(rematches, colourClashes bool) ([][2]int, int, bool) {
+	p.rematches = rematches
+	p.colourClashes = colourClashes
+	p.budget = pairingBudget
+
+	if len(p.order)%2 == 0 {
+		pairs, ok := p.match(p.order)
+		return pairs, -1, ok
+	}
+
+	// The lowest ranked player with the fewest byes sits out, as long as
+	// everyone else can still be paired
+	candidates := make([]int, len(p.order))
+	for k := range candidates {
+		candidates[k] = len(p.order) - 1 - k
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return p.standings[p.order[candidates[a]]].Byes < p.standings[p.order[candidates[b]]].Byes
+	})
+
+	for _, k := range candidates {
+		remaining := append(append([]int(nil), p.order[:k]...), p.order[k+1:]...)
+		if pairs, ok := p.match(remaining); ok {
+			return pairs, p.order[k], true
+		}
+	}
+
+	return nil, -1, false
+}
+
+// match pairs the remaining players, the first one with the best opponent
+// that still lets everyone else be paired
+func (p *pairer) match(remaining []int) ([][2]int, bool) {
+	if len(remaining) == 0 {
+		return nil, true
+	}
+	if p.budget--; p.budget < 0 {
+		return nil, false
+	}
+
+	i, rest := remaining[0], remaining[1:]
+	points := p.standings[i].Points
+
+	// The ideal opponent is the first of the bottom half of the group on the
+	// same points
+	group := 1
+	for _, j := range rest {
+		if p.standings[j].Points == points {
+			group++
+		}
+	}
+	ideal := group/2 - 1
+
+	var candidates []int
+	for k, j := range rest {
+		if !p.rematches && p.met[i][p.standings[j].ID] {
+			continue
+		}
+		if !p.colourClashes && p.absoluteClash(i, j) {
+			continue
+		}
+		candidates = append(candidates, k)
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		x, y := rest[candidates[a]], rest[candidates[b]]
+		dx := math.Abs(points - p.standings[x].Points)
+		dy := math.Abs(points - p.standings[y].Points)
+		if dx != dy {
+			return dx < dy
+		}
+		if cx, cy := p.clash(i, x), p.clash(i, y); cx != cy {
+			return !cx
+		}
+		return abs(candidates[a]-ideal) < abs(candidates[b]-ideal)
+	})
+
+	for _, k := range candidates {
+		others := append(append([]int(nil), rest[:k]...), rest[k+1:]...)
+		if pairs, ok := p.match(others); ok {
+			return append([][2]int{{i, rest[k]}}, pairs...), true
+		}
+	}
+
+	return nil, false
+}
+
+// preference returns the colour a player should get next and how strongly:
+// 0 for no preference, 1 to alternate with the last game, more to even out
+// the colours played so far. A player who had the same colour in their last
+// two games, or two more times than the other one, must get the other colour
+// and has a preference of at least absolutePreference.
+func preference(s Standing) (Colour, int) {
+	balance := 0
+	for _, c := range s.Colours {
+		if c == White {
+			balance++
+		} else {
+			balance--
+		}
+	}
+
+	n := len(s.Colours)
+	switch {
+	case n >= 2 && s.Colours[n-1] == s.Colours[n-2]:
+		return 1 - s.Colours[n-1], max(absolutePreference, 1+abs(balance))
+	case balance > 0:
+		return Black, 1 + balance
+	case balance < 0:
+		return White, 1 - balance
+	case len(s.Colours) > 0:
+		return 1 - s.Colours[len(s.Colours)-1], 1
+	default:
+		return White, 0
+	}
+}
+
+// clash reports whether players i and j want the same colour
+func (p *pairer) clash(i, j int) bool {
+	ci, si := preference(p.standings[i])
+	cj, sj := preference(p.standings[j])
+	return si > 0 && sj > 0 && ci == cj
+}
+
+// absoluteClash reports whether players i and j must both get the same colour
+func (p *pairer) absoluteClash(i, j int) bool {
+	ci, si := preference(p.standings[i])
+	cj, sj := preference(p.standings[j])
+	return si >= absolutePreference && sj >= absolutePreference && ci == cj
+}
+
+// colours returns the players of a game as white and black. The player with
+// the stronger preference gets their colour, the higher ranked one when both
+// are as strong, and colours alternate by board when neither player cares.
+func (p *pairer) colours(i, j, board int) (int, int) {
+	ci, si := preference(p.standings[i])
+	cj, sj := preference(p.standings[j])
+
+	switch {
+	case si == 0 && sj == 0:
+		if board%2 == 0 {
+			return i, j
+		}
+		return j, i
+	case si >= sj && ci == White, si < sj && cj == Black:
+		return i, j
+	default:
+		return j, i
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// SwissOptions configures a SwissEvent
+type SwissOptions struct {
+	// Rate updates the ratings of the players with rating.Rate after every
+	// game
+	Rate bool
+	// Options are the options used to rate games
+	Options *types.OpenSkillOptions
+}
+
+// SwissEvent runs a Swiss event: it pairs every round with PairSwiss and
+// keeps the standings up to date with the results. It is safe for concurrent
+// use.
+type SwissEvent struct {
+	mu        sync.Mutex
+	options   SwissOptions
+	standings []Standing
+	index     map[string]int
+	rounds    int
+	current   *Round
+}
+
+// NewSwissEvent returns a new SwissEvent for players, who may already have
+// points and games from earlier rounds
+func NewSwissEvent(players []Standing, options *SwissOptions) (*SwissEvent, error) {
+	if len(players) < 2 {
+		return nil, fmt.Errorf("%w: got %d", ErrTooFewPlayers, len(players))
+	}
+
+	if options == nil {
+		options = &SwissOptions{}
+	}
+
+	e := &SwissEvent{
+		options:   *options,
+		standings: make([]Standing, len(players)),
+		index:     make(map[string]int),
+	}
+
+	ratings := make(types.Team, len(players))
+	for i, s := range players {
+		if s.ID == "" {
+			return nil, ErrEmptyID
+		}
+		if _, ok := e.index[s.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatePlayer, s.ID)
+		}
+		e.index[s.ID] = i
+		e.standings[i] = copyStanding(s)
+		ratings[i] = s.Rating
+	}
+
+	if err := rating.Validate([]types.Team{ratings}, nil); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Pair pairs the next round. The results of the previous round must have
+// been reported.
+func (e *SwissEvent) Pair() (Round, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil {
+		return Round{}, fmt.Errorf("%w: round %d", ErrRoundInProgress, e.current.Number)
+	}
+
+	round, err := PairSwiss(e.standings)
+	if err != nil {
+		return Round{}, err
+	}
+
+	e.rounds++
+	round.Number = e.rounds
+	e.current = &round
+
+	return copyRound(round), nil
+}
+
+// Report records the results of the round being played, one for each of its
+// pairings in order, and rates the games when SwissOptions.Rate is set
+func (e *SwissEvent) Report(results []Result) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current == nil {
+		return ErrNoRoundInProgress
+	}
+
+	round := e.current
+	if len(results) != len(round.Pairings) {
+		return fmt.Errorf("%w: %d results for %d pairings", ErrResultsMismatch, len(results), len(round.Pairings))
+	}
+
+	// Every game is rated before anything changes, so that a failure leaves
+	// the standings as they were
+	ratings := make([][2]types.Rating, len(results))
+	for board, result := range results {
+		var rank []int
+		switch result {
+		case WhiteWins:
+			rank = []int{1, 2}
+		case BlackWins:
+			rank = []int{2, 1}
+		case Draw:
+			rank = []int{1, 1}
+		default:
+			return fmt.Errorf("%w: unknown result %d on board %d", ErrResultsMismatch, result, board+1)
+		}
+
+		white := e.standings[e.index[round.Pairings[board].White]]
+		black := e.standings[e.index[round.Pairings[board].Black]]
+		ratings[board] = [2]types.Rating{white.Rating, black.Rating}
+
+		if e.options.Rate {
+			o := types.OpenSkillOptions{}
+			if e.options.Options != nil {
+				o = *e.options.Options
+			}
+			o.Rank = rank
+			o.Score = nil
+			o.Scores = nil
+			o.Weight = nil
+
+			teams, err := rating.RateE([]types.Team{{white.Rating}, {black.Rating}}, &o)
+			if err != nil {
+				return err
+			}
+			ratings[board] = [2]types.Rating{teams[0][0], teams[1][0]}
+		}
+	}
+
+	for board, pairing := range round.Pairings {
+		white := &e.standings[e.index[pairing.White]]
+		black := &e.standings[e.index[pairing.Black]]
+
+		switch results[board] {
+		case WhiteWins:
+			white.Points += WinPoints
+		case BlackWins:
+			black.Points += WinPoints
+		case Draw:
+			white.Points += DrawPoints
+			black.Points += DrawPoints
+		}
+
+		white.Opponents = append(white.Opponents, black.ID)
+		black.Opponents = append(black.Opponents, white.ID)
+		white.Colours = append(white.Colours, White)
+		black.Colours = append(black.Colours, Black)
+		white.Rating, black.Rating = ratings[board][0], ratings[board][1]
+	}
+
+	if round.Bye != "" {
+		bye := &e.standings[e.index[round.Bye]]
+		bye.Points += WinPoints
+		bye.Byes++
+	}
+
+	e.current = nil
+
+	return nil
+}
+
+// Standings returns the players ranked by points, then by ordinal
+func (e *SwissEvent) Standings() []Standing {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	standings := make([]Standing, 0, len(e.standings))
+	for _, i := range rank(e.standings) {
+		standings = append(standings, copyStanding(e.standings[i]))
+	}
+	return standings
+}
+
+func copyStanding(s Standing) Standing {
+	s.Opponents = append([]string(nil), s.Opponents...)
+	s.Colours = append([]Colour(nil), s.Colours...)
+	return s
+}
+
+func copyRound(r Round) Round {
+	r.Pairings = append([]Pairing(nil), r.Pairings...)
+	return r
+}
@@ -0,0 +1,341 @@
+package tournament_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/tournament"
+	"github.com/intinig/go-openskill/types"
+)
+
+// entrants returns n players named p1, p2, ... from the strongest down
+func entrants(n int) []tournament.Standing {
+	players := make([]tournament.Standing, n)
+	for i, r := range field(n) {
+		players[i] = tournament.Standing{ID: fmt.Sprintf("p%d", i+1), Rating: r}
+	}
+	return players
+}
+
+// play pairs and reports rounds of e with random results
+func play(t *testing.T, e *tournament.SwissEvent, rounds int, r *rand.Rand) []tournament.Round {
+	t.Helper()
+	var played []tournament.Round
+	for n := 0; n < rounds; n++ {
+		round, err := e.Pair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		results := make([]tournament.Result, len(round.Pairings))
+		for board := range results {
+			results[board] = tournament.Result(r.IntN(3))
+		}
+		if err := e.Report(results); err != nil {
+			t.Fatal(err)
+		}
+		played = append(played, round)
+	}
+	return played
+}
+
+func TestPairSwissFirstRound(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	round, err := tournament.PairSwiss(entrants(8))
+	is.NoErr(err)
+	is.Equal(round.Pairings, []tournament.Pairing{
+		{White: "p1", Black: "p5"},
+		{White: "p6", Black: "p2"},
+		{White: "p3", Black: "p7"},
+		{White: "p8", Black: "p4"},
+	})
+	is.Equal(round.Bye, "")
+}
+
+func TestPairSwissBreaksTiesByOrdinal(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// Given in reverse order, players are still ranked by ordinal
+	players := entrants(4)
+	players[0], players[3] = players[3], players[0]
+	players[1], players[2] = players[2], players[1]
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	is.Equal(round.Pairings, []tournament.Pairing{
+		{White: "p1", Black: "p3"},
+		{White: "p4", Black: "p2"},
+	})
+}
+
+func TestPairSwissScoreGroups(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := entrants(4)
+	players[1].Points = 1
+	players[3].Points = 1
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	is.Equal(round.Pairings, []tournament.Pairing{
+		{White: "p2", Black: "p4"},
+		{White: "p3", Black: "p1"},
+	})
+}
+
+func TestPairSwissAvoidsRematches(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// p1 and p2 lead alone but have already met
+	players := entrants(4)
+	players[0].Points, players[0].Opponents = 1, []string{"p2"}
+	players[1].Points, players[1].Opponents = 1, []string{"p1"}
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	for _, pairing := range round.Pairings {
+		is.True(!(pairing.White == "p1" && pairing.Black == "p2"))
+		is.True(!(pairing.White == "p2" && pairing.Black == "p1"))
+	}
+}
+
+func TestPairSwissRematchesWhenItMust(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := entrants(2)
+	players[0].Opponents = []string{"p2"}
+	players[1].Opponents = []string{"p1"}
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	is.Equal(len(round.Pairings), 1)
+}
+
+func TestPairSwissColours(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// p1 had White twice, p2 had Black last, both must get their due
+	players := entrants(2)
+	players[0].Colours = []tournament.Colour{tournament.White, tournament.White}
+	players[1].Colours = []tournament.Colour{tournament.White, tournament.Black}
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	is.Equal(round.Pairings, []tournament.Pairing{{White: "p2", Black: "p1"}})
+}
+
+func TestPairSwissAvoidsAThirdColourInARow(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// p1 and p2 both had White twice and cannot meet, unless there is nobody
+	// else to pair them with
+	players := entrants(4)
+	for i := range players {
+		players[i].Points = 2
+	}
+	players[0].Colours = []tournament.Colour{tournament.White, tournament.White}
+	players[1].Colours = []tournament.Colour{tournament.White, tournament.White}
+	players[2].Colours = []tournament.Colour{tournament.Black, tournament.Black}
+	players[3].Colours = []tournament.Colour{tournament.Black, tournament.Black}
+
+	round, err := tournament.PairSwiss(players)
+	is.NoErr(err)
+	is.Equal(round.Pairings, []tournament.Pairing{{White: "p3", Black: "p1"}, {White: "p4", Black: "p2"}})
+
+	round, err = tournament.PairSwiss(players[:2])
+	is.NoErr(err)
+	is.Equal(len(round.Pairings), 1)
+}
+
+func TestPairSwissErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	_, err := tournament.PairSwiss(entrants(1))
+	is.True(errors.Is(err, tournament.ErrTooFewPlayers))
+
+	players := entrants(2)
+	players[1].ID = "p1"
+	_, err = tournament.PairSwiss(players)
+	is.True(errors.Is(err, tournament.ErrDuplicatePlayer))
+
+	// An empty ID would be taken for "no bye" when the player sits out
+	players = entrants(3)
+	players[2].ID = ""
+	_, err = tournament.PairSwiss(players)
+	is.True(errors.Is(err, tournament.ErrEmptyID))
+}
+
+func TestSwissEvent(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	e, err := tournament.NewSwissEvent(entrants(10), nil)
+	is.NoErr(err)
+
+	rounds := play(t, e, 5, rand.New(rand.NewPCG(1, 1)))
+	for n, round := range rounds {
+		is.Equal(round.Number, n+1)
+		is.Equal(len(round.Pairings), 5)
+	}
+
+	total := 0.0
+	for _, s := range e.Standings() {
+		total += s.Points
+		is.Equal(len(s.Opponents), 5)
+
+		// Nobody meets the same player twice
+		met := make(map[string]bool)
+		for _, id := range s.Opponents {
+			is.True(!met[id])
+			met[id] = true
+		}
+
+		// Nobody has more than one extra game with either colour
+		whites := 0
+		for _, c := range s.Colours {
+			if c == tournament.White {
+				whites++
+			}
+		}
+		is.True(whites >= 2 && whites <= 3)
+	}
+	is.Equal(total, 25.0) // one point per game
+
+	// Standings are ordered by points
+	standings := e.Standings()
+	for i := 1; i < len(standings); i++ {
+		is.True(standings[i-1].Points >= standings[i].Points)
+	}
+}
+
+func TestSwissEventByes(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	e, err := tournament.NewSwissEvent(entrants(5), nil)
+	is.NoErr(err)
+
+	rounds := play(t, e, 5, rand.New(rand.NewPCG(2, 2)))
+
+	// Everyone sits out once
+	byes := make(map[string]bool)
+	for _, round := range rounds {
+		is.True(round.Bye != "")
+		is.True(!byes[round.Bye])
+		byes[round.Bye] = true
+	}
+	is.Equal(rounds[0].Bye, "p5")
+
+	for _, s := range e.Standings() {
+		is.Equal(s.Byes, 1)
+		is.Equal(len(s.Opponents), 4)
+	}
+}
+
+func TestSwissEventNeverGivesAColourThreeTimesInARow(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct{ players, rounds int }{{16, 7}, {33, 9}, {64, 9}} {
+		for seed := uint64(1); seed <= 5; seed++ {
+			t.Run(fmt.Sprintf("%dPlayers/%d", tt.players, seed), func(t *testing.T) {
+				t.Parallel()
+				is := _is.New(t)
+
+				e, err := tournament.NewSwissEvent(entrants(tt.players), nil)
+				is.NoErr(err)
+				play(t, e, tt.rounds, rand.New(rand.NewPCG(seed, seed)))
+
+				for _, s := range e.Standings() {
+					for k := 2; k < len(s.Colours); k++ {
+						is.True(s.Colours[k] != s.Colours[k-1] || s.Colours[k] != s.Colours[k-2]) // same colour three times in a row
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSwissEventRates(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := entrants(2)
+
+	unrated, err := tournament.NewSwissEvent(players, nil)
+	is.NoErr(err)
+	rated, err := tournament.NewSwissEvent(players, &tournament.SwissOptions{Rate: true})
+	is.NoErr(err)
+
+	for _, e := range []*tournament.SwissEvent{unrated, rated} {
+		round, err := e.Pair()
+		is.NoErr(err)
+		is.Equal(round.Pairings, []tournament.Pairing{{White: "p1", Black: "p2"}})
+		is.NoErr(e.Report([]tournament.Result{tournament.BlackWins}))
+	}
+
+	want := rating.Rate([]types.Team{{players[0].Rating}, {players[1].Rating}}, &types.OpenSkillOptions{
+		Rank: []int{2, 1},
+	})
+
+	standings := rated.Standings()
+	is.Equal(standings[0].ID, "p2")
+	is.Equal(standings[0].Rating, want[1][0])
+	is.Equal(standings[1].Rating, want[0][0])
+
+	standings = unrated.Standings()
+	is.Equal(standings[0].Rating, players[1].Rating)
+	is.Equal(standings[1].Rating, players[0].Rating)
+}
+
+func TestSwissEventErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	_, err := tournament.NewSwissEvent(entrants(1), nil)
+	is.True(errors.Is(err, tournament.ErrTooFewPlayers))
+
+	players := entrants(2)
+	players[1].ID = "p1"
+	_, err = tournament.NewSwissEvent(players, nil)
+	is.True(errors.Is(err, tournament.ErrDuplicatePlayer))
+
+	players = entrants(3)
+	players[2].ID = ""
+	_, err = tournament.NewSwissEvent(players, nil)
+	is.True(errors.Is(err, tournament.ErrEmptyID))
+
+	players = entrants(2)
+	players[1].Rating.Sigma = 0
+	_, err = tournament.NewSwissEvent(players, nil)
+	is.True(errors.Is(err, rating.ErrInvalidSigma))
+
+	e, err := tournament.NewSwissEvent(entrants(4), nil)
+	is.NoErr(err)
+	is.True(errors.Is(e.Report(nil), tournament.ErrNoRoundInProgress))
+
+	_, err = e.Pair()
+	is.NoErr(err)
+	_, err = e.Pair()
+	is.True(errors.Is(err, tournament.ErrRoundInProgress))
+	is.True(errors.Is(e.Report([]tournament.Result{tournament.Draw}), tournament.ErrResultsMismatch))
+	is.True(errors.Is(e.Report([]tournament.Result{tournament.Draw, 7}), tournament.ErrResultsMismatch))
+
+	// Failed reports leave the round open
+	is.NoErr(e.Report([]tournament.Result{tournament.Draw, tournament.WhiteWins}))
+	for _, s := range e.Standings() {
+		is.Equal(len(s.Opponents), 1)
+	}
+}
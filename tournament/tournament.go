@@ -0,0 +1,205 @@
+// Package tournament forecasts events from the current ratings of their
+// players, by simulating them many times
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/types"
+)
+
+// ErrTooFewPlayers is returned when an event has less than two players
+var ErrTooFewPlayers = errors.New("openskill: too few players")
+
+// Format is the way an event is played. Players are given in seed order, the
+// first one being the top seed.
+type Format interface {
+	// play plays the event once, with win[i][j] being the probability of
+	// player i beating player j, and returns the finishing position of each
+	// player, starting from 1
+	play(win [][]float64, r *rand.Rand) []int
+}
+
+// Forecast is the outcome of simulating an event many times
+type Forecast struct {
+	// Title is the probability of each player winning the event
+	Title []float64
+	// Finish holds, for each player, the probability of finishing in each
+	// position: Finish[i][k] is the probability of player i finishing in
+	// position k+1. Players knocked out in the same round of an elimination
+	// event share the best position of their round, for instance 3rd for
+	// both losing semi-finalists.
+	Finish [][]float64
+	// ExpectedFinish is the average finishing position of each player
+	ExpectedFinish []float64
+}
+
+// Advancement returns the probability of each player finishing in the top
+// places of the event. In elimination events this is the probability of
+// reaching the round where top players are left, for instance 8 for the
+// quarter-finals.
+func (f Forecast) Advancement(top int) []float64 {
+	advancement := make([]float64, len(f.Finish))
+	for i, finish := range f.Finish {
+		for k := 0; k < top && k < len(finish); k++ {
+			advancement[i] += finish[k]
+		}
+	}
+	return advancement
+}
+
+// Simulate plays the event many times and returns how the players fared.
+// Players are given in seed order, see Seed. The outcome of every game comes
+// from rating.PredictWin between the two players, so the outcome fields of
+// options, Rank, Score, Scores and Weight, are ignored. Only
+// simulation.Samples and simulation.Rand are used.
+func Simulate(players []types.Rating, format Format, options *types.OpenSkillOptions, simulation *rating.SimulationOptions) (Forecast, error) {
+	if len(players) < 2 {
+		return Forecast{}, fmt.Errorf("%w: got %d", ErrTooFewPlayers, len(players))
+	}
+
+	options = gameOptions(options)
+	if err := rating.Validate([]types.Team{players}, options); err != nil {
+		return Forecast{}, err
+	}
+
+	if simulation == nil {
+		simulation = &rating.SimulationOptions{}
+	}
+
+	samples := simulation.Samples
+	if samples <= 0 {
+		samples = rating.DefaultSamples
+	}
+
+	r := simulation.Rand
+	if r == nil {
+		r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	win := winMatrix(players, options)
+	n := len(players)
+
+	counts := make([][]int, n)
+	for i := range counts {
+		counts[i] = make([]int, n)
+	}
+
+	for s := 0; s < samples; s++ {
+		for i, finish := range format.play(win, r) {
+			counts[i][finish-1]++
+		}
+	}
+
+	forecast := Forecast{
+		Title:          make([]float64, n),
+		Finish:         make([][]float64, n),
+		ExpectedFinish: make([]float64, n),
+	}
+	for i := range players {
+		forecast.Finish[i] = make([]float64, n)
+		for k, count := range counts[i] {
+			p := float64(count) / float64(samples)
+			forecast.Finish[i][k] = p
+			forecast.ExpectedFinish[i] += p * float64(k+1)
+		}
+		forecast.Title[i] = forecast.Finish[i][0]
+	}
+
+	return forecast, nil
+}
+
+// Seed returns the recommended seeding of players, as their indexes from the
+// top seed to the bottom one. Players are seeded by ordinal, so that the ones
+// that are both strong and well known are kept apart the longest.
+func Seed(players []types.Rating) []int {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rating.Ordinal(players[order[a]]) > rating.Ordinal(players[order[b]])
+	})
+	return order
+}
+
+// gameOptions returns a copy of options without the outcome of a match, since
+// every game of an event has its own
+func gameOptions(options *types.OpenSkillOptions) *types.OpenSkillOptions {
+	if options == nil {
+		return nil
+	}
+
+	o := *options
+	o.Rank = nil
+	o.Score = nil
+	o.Scores = nil
+	o.Weight = nil
+	return &o
+}
+
+// winMatrix returns the probability of each player beating each other player
+func winMatrix(players []types.Rating, options *types.OpenSkillOptions) [][]float64 {
+	win := make([][]float64, len(players))
+	for i := range win {
+		win[i] = make([]float64, len(players))
+	}
+
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			p := rating.PredictWin([]types.Team{{players[i]}, {players[j]}}, options)
+			win[i][j] = p[0]
+			win[j][i] = p[1]
+		}
+	}
+
+	return win
+}
+
+// game plays a game between players a and b and returns the winner and the
+// loser. A bye, represented by -1, always loses.
+func game(win [][]float64, r *rand.Rand, a, b int) (int, int) {
+	switch {
+	case a < 0:
+		return b, a
+	case b < 0:
+		return a, b
+	case r.Float64() < win[a][b]:
+		return a, b
+	default:
+		return b, a
+	}
+}
+
+// positions turns how long each player lasted into finishing positions,
+// players that lasted equally long share the best position
+func positions(lasted []int) []int {
+	finish := make([]int, len(lasted))
+	for i := range lasted {
+		finish[i] = 1
+		for j := range lasted {
+			if lasted[j] > lasted[i] {
+				finish[i]++
+			}
+		}
+	}
+	return finish
+}
+
+// standings turns points into finishing positions, breaking ties at random
+func standings(points []int, r *rand.Rand) []int {
+	order := r.Perm(len(points))
+	sort.SliceStable(order, func(a, b int) bool {
+		return points[order[a]] > points[order[b]]
+	})
+
+	finish := make([]int, len(points))
+	for k, i := range order {
+		finish[i] = k + 1
+	}
+	return finish
+}
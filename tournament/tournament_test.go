@@ -0,0 +1,210 @@
+package tournament_test
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/ptr"
+	"github.com/intinig/go-openskill/rating"
+	"github.com/intinig/go-openskill/tournament"
+	"github.com/intinig/go-openskill/types"
+)
+
+// field returns n players given in seed order, each one stronger than the
+// next
+func field(n int) []types.Rating {
+	players := make([]types.Rating, n)
+	for i := range players {
+		players[i] = rating.NewWithOptions(&types.OpenSkillOptions{
+			Mu:    ptr.Float64(40 - 2*float64(i)),
+			Sigma: ptr.Float64(3),
+		})
+	}
+	return players
+}
+
+func simulation(seed uint64) *rating.SimulationOptions {
+	return &rating.SimulationOptions{
+		Samples: 20000,
+		Rand:    rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+var formats = map[string]tournament.Format{
+	"single elimination": tournament.SingleElimination{},
+	"double elimination": tournament.DoubleElimination{},
+	"round robin":        tournament.RoundRobin{},
+	"swiss":              tournament.Swiss{},
+}
+
+func TestSimulateTitleOddsAddUp(t *testing.T) {
+	t.Parallel()
+
+	for name, format := range formats {
+		for _, n := range []int{2, 3, 5, 8} {
+			is := _is.New(t)
+
+			forecast, err := tournament.Simulate(field(n), format, nil, simulation(1))
+			is.NoErr(err)
+			is.Equal(len(forecast.Title), n)
+
+			total := 0.0
+			for i, title := range forecast.Title {
+				total += title
+				is.Equal(title, forecast.Finish[i][0])
+
+				finish := 0.0
+				for _, p := range forecast.Finish[i] {
+					finish += p
+				}
+				is.True(math.Abs(finish-1) < 1e-9) // every player finishes somewhere
+			}
+			if math.Abs(total-1) > 1e-9 {
+				t.Errorf("%s with %d players: title odds add up to %v", name, n, total)
+			}
+		}
+	}
+}
+
+func TestSimulateFavoursStrongerPlayers(t *testing.T) {
+	t.Parallel()
+
+	for name, format := range formats {
+		forecast, err := tournament.Simulate(field(8), format, nil, simulation(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 1; i < 8; i++ {
+			if forecast.Title[i] > forecast.Title[i-1]+0.01 {
+				t.Errorf("%s: seed %d has better title odds than seed %d", name, i+1, i)
+			}
+			if forecast.ExpectedFinish[i] < forecast.ExpectedFinish[i-1]-0.01 {
+				t.Errorf("%s: seed %d has a better expected finish than seed %d", name, i+1, i)
+			}
+		}
+	}
+}
+
+func TestSimulateHeadToHead(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := field(2)
+	want := rating.PredictWin([]types.Team{{players[0]}, {players[1]}}, nil)[0]
+
+	forecast, err := tournament.Simulate(players, tournament.SingleElimination{}, nil, &rating.SimulationOptions{
+		Samples: 200000,
+		Rand:    rand.New(rand.NewPCG(3, 3)),
+	})
+	is.NoErr(err)
+	is.True(math.Abs(forecast.Title[0]-want) < 0.005)
+	is.Equal(forecast.Advancement(2), []float64{1, 1})
+}
+
+func TestSimulateSingleEliminationByes(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// With 3 players the top seed goes straight to the final
+	forecast, err := tournament.Simulate(field(3), tournament.SingleElimination{}, nil, simulation(4))
+	is.NoErr(err)
+	is.Equal(forecast.Advancement(2)[0], 1.0)
+	is.Equal(forecast.Finish[0][2], 0.0)
+	is.True(forecast.Finish[1][2] > 0) // the losing semi-finalist is 3rd
+}
+
+func TestSimulateSharedPositions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// Both losing semi-finalists finish 3rd, nobody finishes 4th
+	forecast, err := tournament.Simulate(field(4), tournament.SingleElimination{}, nil, simulation(5))
+	is.NoErr(err)
+	for i := range forecast.Finish {
+		is.Equal(forecast.Finish[i][3], 0.0)
+	}
+
+	advancement := forecast.Advancement(4)
+	for _, p := range advancement {
+		is.True(math.Abs(p-1) < 1e-9)
+	}
+}
+
+func TestSimulateIsReproducible(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	for _, format := range formats {
+		a, err := tournament.Simulate(field(6), format, nil, simulation(6))
+		is.NoErr(err)
+		b, err := tournament.Simulate(field(6), format, nil, simulation(6))
+		is.NoErr(err)
+		is.Equal(a, b)
+	}
+}
+
+func TestSimulateDoubleEliminationSecondChance(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// A second chance makes the favourite more likely to win the title
+	single, err := tournament.Simulate(field(8), tournament.SingleElimination{}, nil, simulation(7))
+	is.NoErr(err)
+	double, err := tournament.Simulate(field(8), tournament.DoubleElimination{}, nil, simulation(7))
+	is.NoErr(err)
+	is.True(double.Title[0] > single.Title[0])
+}
+
+func TestSimulateErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	_, err := tournament.Simulate(field(1), tournament.RoundRobin{}, nil, nil)
+	is.True(errors.Is(err, tournament.ErrTooFewPlayers))
+
+	players := field(2)
+	players[1].Sigma = -1
+	_, err = tournament.Simulate(players, tournament.RoundRobin{}, nil, nil)
+	is.True(errors.Is(err, rating.ErrInvalidSigma))
+
+	_, err = tournament.Simulate(field(3), tournament.RoundRobin{}, &types.OpenSkillOptions{Beta: ptr.Float64(0)}, nil)
+	is.True(errors.Is(err, rating.ErrInvalidOption))
+}
+
+func TestSimulateIgnoresMatchOutcomeOptions(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	// Weights, ranks and scores meant for another match do not apply to games
+	options := &types.OpenSkillOptions{
+		Weight: [][]float64{{0.5}},
+		Rank:   []int{1},
+		Score:  []int{1, 2, 3, 4},
+	}
+	for _, format := range formats {
+		got, err := tournament.Simulate(field(3), format, options, simulation(8))
+		is.NoErr(err)
+		want, err := tournament.Simulate(field(3), format, nil, simulation(8))
+		is.NoErr(err)
+		is.Equal(got, want)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	players := []types.Rating{
+		rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(30), Sigma: ptr.Float64(8)}),
+		rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(28), Sigma: ptr.Float64(1)}),
+		rating.NewWithOptions(&types.OpenSkillOptions{Mu: ptr.Float64(20), Sigma: ptr.Float64(1)}),
+	}
+
+	// The second player is less strong but much better known
+	is.Equal(tournament.Seed(players), []int{1, 2, 0})
+}
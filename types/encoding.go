@@ -0,0 +1,135 @@
+package types
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// RatingVersion is the version of the encoding written by the Marshal methods
+// of Rating, it is bumped whenever the encoding changes
+const RatingVersion = 1
+
+var (
+	// ErrUnsupportedVersion is returned when decoding a rating written with a
+	// version of the encoding this package does not know about
+	ErrUnsupportedVersion = errors.New("openskill: unsupported rating encoding version")
+	// ErrInvalidEncoding is returned when decoding a rating that is truncated
+	// or malformed
+	ErrInvalidEncoding = errors.New("openskill: invalid rating encoding")
+)
+
+var (
+	_ json.Marshaler             = Rating{}
+	_ json.Unmarshaler           = (*Rating)(nil)
+	_ encoding.BinaryMarshaler   = Rating{}
+	_ encoding.BinaryUnmarshaler = (*Rating)(nil)
+)
+
+// ratingJSON is the JSON representation of a Rating
+type ratingJSON struct {
+	Version    int        `json:"version"`
+	Mu         float64    `json:"mu"`
+	Sigma      float64    `json:"sigma"`
+	Z          int        `json:"z"`
+	LastPlayed *time.Time `json:"last_played,omitempty"`
+}
+
+// MarshalJSON encodes the rating as a JSON object holding the version of the
+// encoding, LastPlayed is omitted when it is zero
+func (r Rating) MarshalJSON() ([]byte, error) {
+	v := ratingJSON{
+		Version: RatingVersion,
+		Mu:      r.Mu,
+		Sigma:   r.Sigma,
+		Z:       r.Z,
+	}
+	if !r.LastPlayed.IsZero() {
+		v.LastPlayed = &r.LastPlayed
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a rating written by MarshalJSON
+func (r *Rating) UnmarshalJSON(data []byte) error {
+	var v ratingJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+
+	if v.Version != RatingVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, v.Version)
+	}
+
+	*r = Rating{
+		Mu:    v.Mu,
+		Sigma: v.Sigma,
+		Z:     v.Z,
+	}
+	if v.LastPlayed != nil {
+		r.LastPlayed = *v.LastPlayed
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the rating as a version byte, followed by mu and
+// sigma as big endian float64, z as a varint and, when it is not zero,
+// LastPlayed as encoded by time.Time.MarshalBinary
+func (r Rating) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 1+8+8, 1+8+8+binary.MaxVarintLen64)
+	data[0] = RatingVersion
+	binary.BigEndian.PutUint64(data[1:], math.Float64bits(r.Mu))
+	binary.BigEndian.PutUint64(data[9:], math.Float64bits(r.Sigma))
+	data = binary.AppendVarint(data, int64(r.Z))
+
+	if !r.LastPlayed.IsZero() {
+		lastPlayed, err := r.LastPlayed.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, lastPlayed...)
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a rating written by MarshalBinary
+func (r *Rating) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: empty data", ErrInvalidEncoding)
+	}
+
+	if data[0] != RatingVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, data[0])
+	}
+
+	if len(data) < 1+8+8 {
+		return fmt.Errorf("%w: got %d bytes", ErrInvalidEncoding, len(data))
+	}
+
+	z, n := binary.Varint(data[17:])
+	if n <= 0 || z < math.MinInt32 || z > math.MaxInt32 {
+		return fmt.Errorf("%w: bad z", ErrInvalidEncoding)
+	}
+
+	decoded := Rating{
+		Mu:    math.Float64frombits(binary.BigEndian.Uint64(data[1:])),
+		Sigma: math.Float64frombits(binary.BigEndian.Uint64(data[9:])),
+		Z:     int(z),
+	}
+
+	if rest := data[17+n:]; len(rest) > 0 {
+		if err := decoded.LastPlayed.UnmarshalBinary(rest); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+		}
+	}
+
+	*r = decoded
+	return nil
+}
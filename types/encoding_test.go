@@ -0,0 +1,105 @@
+package types_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	_is "github.com/matryer/is"
+
+	"github.com/intinig/go-openskill/types"
+)
+
+var lastPlayed = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func TestRatingJSON(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	data, err := json.Marshal(types.Rating{Mu: 25, Sigma: 25 / 3.0, Z: 3})
+	is.NoErr(err)
+	is.Equal(string(data), `{"version":1,"mu":25,"sigma":8.333333333333334,"z":3}`)
+
+	data, err = json.Marshal(types.Rating{Mu: 25, Sigma: 2, Z: 3, LastPlayed: lastPlayed})
+	is.NoErr(err)
+	is.Equal(string(data), `{"version":1,"mu":25,"sigma":2,"z":3,"last_played":"2024-03-01T12:30:00Z"}`)
+}
+
+func TestRatingJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	teams := []types.Team{
+		{{Mu: 29.182, Sigma: 4.782, Z: 3}, {Mu: -5.5, Sigma: 0.1, Z: 2}},
+		{{Mu: math.MaxFloat64, Sigma: math.SmallestNonzeroFloat64, Z: 3, LastPlayed: lastPlayed}},
+	}
+
+	data, err := json.Marshal(teams)
+	is.NoErr(err)
+
+	var decoded []types.Team
+	is.NoErr(json.Unmarshal(data, &decoded))
+	is.Equal(decoded, teams)
+}
+
+func TestRatingJSONErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	var r types.Rating
+	is.True(errors.Is(json.Unmarshal([]byte(`{"version":2,"mu":25,"sigma":2,"z":3}`), &r), types.ErrUnsupportedVersion))
+	is.True(errors.Is(json.Unmarshal([]byte(`{"mu":25,"sigma":2,"z":3}`), &r), types.ErrUnsupportedVersion))
+	is.True(errors.Is(json.Unmarshal([]byte(`{"version":1,"mu":"25"}`), &r), types.ErrInvalidEncoding))
+	is.Equal(r, types.Rating{})
+}
+
+func TestRatingBinaryRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, r := range []types.Rating{
+		{Mu: 25, Sigma: 25 / 3.0, Z: 3},
+		{Mu: -5.5, Sigma: 0.1, Z: -2},
+		{Mu: math.Inf(1), Sigma: math.SmallestNonzeroFloat64, Z: math.MaxInt32},
+		{Mu: 29.182, Sigma: 4.782, Z: 3, LastPlayed: lastPlayed},
+	} {
+		is := _is.New(t)
+
+		data, err := r.MarshalBinary()
+		is.NoErr(err)
+		is.Equal(data[0], byte(types.RatingVersion))
+
+		var decoded types.Rating
+		is.NoErr(decoded.UnmarshalBinary(data))
+		is.Equal(decoded, r)
+	}
+}
+
+func TestRatingBinaryIsCompact(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	data, err := types.Rating{Mu: 25, Sigma: 25 / 3.0, Z: 3}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(len(data), 18)
+}
+
+func TestRatingBinaryErrors(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+
+	data, err := types.Rating{Mu: 25, Sigma: 2, Z: 3, LastPlayed: lastPlayed}.MarshalBinary()
+	is.NoErr(err)
+
+	var r types.Rating
+	is.True(errors.Is(r.UnmarshalBinary(nil), types.ErrInvalidEncoding))
+	is.True(errors.Is(r.UnmarshalBinary(data[:10]), types.ErrInvalidEncoding))
+	is.True(errors.Is(r.UnmarshalBinary(data[:17]), types.ErrInvalidEncoding))
+	is.True(errors.Is(r.UnmarshalBinary(data[:len(data)-1]), types.ErrInvalidEncoding))
+
+	unknown := append([]byte(nil), data...)
+	unknown[0] = 2
+	is.True(errors.Is(r.UnmarshalBinary(unknown), types.ErrUnsupportedVersion))
+	is.Equal(r, types.Rating{})
+}
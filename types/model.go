@@ -1,5 +1,15 @@
 package types
 
+import "time"
+
 type RatingModel interface {
 	Rate(teams []Team, options *OpenSkillOptions) []Team
 }
+
+// SigmaDecay inflates the sigma of a rating that has not played for a while
+type SigmaDecay interface {
+	// Decay returns r as it is at now, given that it last played at
+	// r.LastPlayed. It never modifies r.LastPlayed, so it is meant to be
+	// applied to the stored rating on every read, not to a decayed one.
+	Decay(r Rating, now time.Time) Rating
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // Team represents a team of players
 type Team []Rating
 
@@ -9,28 +11,37 @@ type TeamRating struct {
 	TeamSigmaSquared float64
 	Team             Team
 	Rank             int
+	// Weight is the weight of each player on the team, it is nil when no
+	// weights were provided and every player counts in full
+	Weight []float64
+	// Score is the score of the team, it is only set when scores were provided
+	Score float64
 }
 
 type OpenSkillOptions struct {
 	// Z is the number of standard deviations a rating can deviate from the mean
-	// before it is considered to be outside the "normal" range of skill.
-	// The default value is 3.29, which covers approximately 99.7% of the normal
-	// distribution.
+	// before it is considered to be outside the "normal" range of skill, it is
+	// used by the ordinal. The default value is 3, which covers approximately
+	// 99.7% of the normal distribution.
 	Z *int
 	// Mu is the mean of the rating distribution.
 	// The default value is 25.0.
 	Mu *float64
 	// Sigma is the standard deviation of the rating distribution.
-	// The default value is 25.0 / 3.29 = 7.59.
+	// The default value is Mu / Z, 25.0 / 3 = 8.333 with the default Mu and Z.
 	Sigma *float64
-	// Epsilon is the minimum change in rating required for a player to gain or
-	// lose rating. The default value is 0.000001.
+	// Epsilon is a small number that keeps the models numerically stable, it
+	// is the default value of Kappa. The default value is 0.0001.
 	Epsilon *float64
-	// Beta is the "precision" of the rating distribution.
-	// The default value is 0.5.
+	// DrawMargin is the performance difference, in the same units as Mu,
+	// within which the Thurstone-Mosteller models consider two teams to have
+	// drawn. The default value is 0.1.
+	DrawMargin *float64
+	// Beta is the variability of the performance of a player in a match.
+	// The default value is Sigma / 2.
 	Beta *float64
 	// Model is the rating model to use.
-	// The default value is Glicko2.
+	// The default value is Plackett-Luce.
 	Model RatingModel
 	// Rank is the rank of each team, where 0 is the highest rank. The default value
 	// is [0, 1, ...]. This is only supported when passed through the Rate function
@@ -38,16 +49,43 @@ type OpenSkillOptions struct {
 	// Score is the score of each team. It is optional and used only if Rank
 	// is not specified.
 	Score []int
-	// Weight is the weight of each player on a team
+	// Scores is like Score, for scores that are not whole numbers such as lap
+	// times. It takes precedence over Score when both are set.
+	Scores []float64
+	// LowerIsBetter ranks teams with lower scores first, for instance when
+	// scores are times. The default value is false.
+	LowerIsBetter bool
+	// Weight is the weight of each player on a team, for instance the fraction
+	// of the match they took part in. It scales both the contribution of the
+	// player to their team and the size of their own update. The default
+	// weight is 1.0. A team whose players all have a weight of 0 did not take
+	// part: rating.Rate leaves it out of the match and returns it unchanged.
 	Weight [][]float64
-	// Tau is the "precision" of the rating distribution.
-	// The default value is 0.5.
+	// Tau is the additive dynamics factor, its square is added to the
+	// variance of every player before a match so that sigma never gets too
+	// small. It is not applied when nil.
 	Tau *float64
 	// PreventSigmaIncrease is a flag that prevents sigma from increasing.
 	// The default value is false.
 	PreventSigmaIncrease bool
 	// Gamma is a function that returns the dynamic factor for a given rating.
 	Gamma func(TeamRating) float64
+	// Margin enables the margin-of-victory mode when Score is provided. Teams
+	// whose scores are within Margin of each other are treated as a draw, and
+	// a score difference d larger than Margin scales the mu update of the
+	// teams by 1 + ln(d / Margin). It is disabled when nil or 0.
+	Margin *float64
+	// Kappa is the lower bound of the factor used to shrink sigma after a
+	// match, it prevents the variance from becoming too small or negative.
+	// The default value is Epsilon.
+	Kappa *float64
+	// Now is when the match is played. When it is set Rate stamps the
+	// LastPlayed of every rating with it, and Decay is applied up to it.
+	Now time.Time
+	// Decay inflates the sigma of the players according to the time elapsed
+	// since they last played, before rating them. It is only applied when Now
+	// is set. PreventSigmaIncrease then compares against the decayed sigma.
+	Decay SigmaDecay
 }
 
 // Rating represents a rating.
@@ -55,4 +93,7 @@ type Rating struct {
 	Mu    float64
 	Sigma float64
 	Z     int
+	// LastPlayed is when the rating was last updated by a match rated with a
+	// Now, it is zero when unknown
+	LastPlayed time.Time
 }
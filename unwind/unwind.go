@@ -50,3 +50,24 @@ func Teams(src []types.Team, rank []int) ([]types.Team, []int) {
 
 	return dest, tenet
 }
+
+// Reorder reorders any per team slice with the tenet returned by Teams, so that
+// it keeps following the teams it belongs to
+func Reorder[T any](src []T, tenet []int) []T {
+	if src == nil {
+		return nil
+	}
+
+	dest := make([]T, len(tenet))
+	for i, index := range tenet {
+		dest[i] = src[index]
+	}
+
+	return dest
+}
+
+// Weights reorders a set of per team weights with the tenet returned by Teams,
+// so that they keep following the teams they belong to
+func Weights(src [][]float64, tenet []int) [][]float64 {
+	return Reorder(src, tenet)
+}
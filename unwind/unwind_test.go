@@ -160,3 +160,25 @@ func TestUnwindTeamsAcceptsDuplicateIndexes(t *testing.T) {
 	teams, _ = unwind.Teams(teams, tenet)
 	is.Equal(teams, src)
 }
+
+func TestUnwindWeightsFollowsTheTenet(t *testing.T) {
+	t.Parallel()
+	is := _is.New(t)
+	src := []types.Team{
+		{
+			test.Teams["a1"],
+		},
+		{
+			test.Teams["b1"],
+			test.Teams["c1"],
+		},
+		{
+			test.Teams["d1"],
+		},
+	}
+	weights := [][]float64{{0.1}, {0.2, 0.3}, {0.4}}
+	_, tenet := unwind.Teams(src, []int{1, 2, 0})
+
+	is.Equal(unwind.Weights(weights, tenet), [][]float64{{0.4}, {0.1}, {0.2, 0.3}})
+	is.Equal(unwind.Weights(nil, tenet), nil)
+}
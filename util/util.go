@@ -30,21 +30,56 @@ func NewWithOptions(options *Options) *Util {
 	}
 }
 
+// Scores returns the score of each team as a float64, taken from
+// options.Scores or, when it is nil, from options.Score. It returns nil when
+// no scores were provided.
+func Scores(options *types.OpenSkillOptions) []float64 {
+	if options.Scores != nil {
+		return options.Scores
+	}
+
+	if options.Score == nil {
+		return nil
+	}
+
+	scores := make([]float64, len(options.Score))
+	for i, score := range options.Score {
+		scores[i] = float64(score)
+	}
+
+	return scores
+}
+
 // TeamRating aggregates a rating for all teams and returns the reduced data
-// structure
+// structure, players are weighted according to options.Weight
 func (u *Util) TeamRating(teams []types.Team, options *types.OpenSkillOptions) []types.TeamRating {
 	if options == nil {
 		options = &types.OpenSkillOptions{}
 	}
 
 	teamRankings := u.Rankings(teams, options.Rank)
+	scores := Scores(options)
 	teamRatings := make([]types.TeamRating, len(teams))
 	for i, team := range teams {
+		var weight []float64
+		if options.Weight != nil {
+			weight = options.Weight[i]
+		}
+
 		tMu := 0.0
 		tSigmaSquare := 0.0
-		for _, rating := range team {
-			tMu += rating.Mu
-			tSigmaSquare += rating.Sigma * rating.Sigma
+		for j, rating := range team {
+			w := 1.0
+			if weight != nil {
+				w = weight[j]
+			}
+			tMu += w * rating.Mu
+			tSigmaSquare += w * w * rating.Sigma * rating.Sigma
+		}
+
+		var score float64
+		if scores != nil {
+			score = scores[i]
 		}
 
 		teamRatings[i] = types.TeamRating{
@@ -52,6 +87,8 @@ func (u *Util) TeamRating(teams []types.Team, options *types.OpenSkillOptions) [
 			TeamSigmaSquared: tSigmaSquare,
 			Team:             team,
 			Rank:             teamRankings[i],
+			Weight:           weight,
+			Score:            score,
 		}
 	}
 	return teamRatings
@@ -69,34 +106,197 @@ func (u *Util) C(teamRatings []types.TeamRating) float64 {
 
 // A returns an array with the number of draws per each team
 func (u *Util) A(teamRatings []types.TeamRating) []int {
+	counts := make(map[int]int)
+	for _, teamRating := range teamRatings {
+		counts[teamRating.Rank]++
+	}
+
 	returning := make([]int, len(teamRatings))
 	for i, teamRating := range teamRatings {
-		for _, teamRating2 := range teamRatings {
-			if teamRating.Rank == teamRating2.Rank {
-				returning[i]++
-			}
-		}
+		returning[i] = counts[teamRating.Rank]
 	}
 	return returning
 }
 
-// SumQ returns the sum of the Q function (iMu/c) for each team
+// SumQ returns the sum of the Q function (iMu/c) for each team. The sums
+// overflow to +Inf for large mu values, use LogSumQ when that can happen.
 func (u *Util) SumQ(teamRatings []types.TeamRating, c float64) []float64 {
 	returning := make([]float64, len(teamRatings))
-	for i, teamRating := range teamRatings {
-		var lowerRanked []types.TeamRating
-		for _, teamRating2 := range teamRatings {
-			if teamRating2.Rank >= teamRating.Rank {
-				lowerRanked = append(lowerRanked, teamRating2)
-			}
+
+	// Walk the ranks from the worst to the best, so that each group only adds
+	// its own teams to the sum of the groups ranked below it
+	groups := u.RankGroups(teamRatings)
+	sum := 0.0
+	for g := len(groups) - 1; g >= 0; g-- {
+		for _, i := range groups[g] {
+			sum += math.Exp(teamRatings[i].TeamMu / c)
+		}
+		for _, i := range groups[g] {
+			returning[i] = sum
+		}
+	}
+	return returning
+}
+
+// Score returns the outcome of a pairwise comparison from the point of view
+// of the team ranked i against the team ranked q: 1.0 is a win, 0.0 is a loss
+// and 0.5 is a draw
+func (u *Util) Score(q, i int) float64 {
+	if q < i {
+		return 0.0
+	}
+
+	if q > i {
+		return 1.0
+	}
+
+	return 0.5
+}
+
+// LadderPairs returns, for each team, its neighbours in the ladder: the team
+// right before and the team right after it, when they exist
+func (u *Util) LadderPairs(teamRatings []types.TeamRating) [][]types.TeamRating {
+	returning := make([][]types.TeamRating, len(teamRatings))
+	for i := range teamRatings {
+		var pairs []types.TeamRating
+		if i > 0 {
+			pairs = append(pairs, teamRatings[i-1])
+		}
+		if i < len(teamRatings)-1 {
+			pairs = append(pairs, teamRatings[i+1])
+		}
+		returning[i] = pairs
+	}
+	return returning
+}
+
+// V is the additive correction of the mean of a Gaussian truncated at t,
+// used by the Thurstone-Mosteller models for wins and losses
+func (u *Util) V(x, t float64) float64 {
+	xt := x - t
+	denom := phiMajor(xt)
+	if denom < machineEpsilon {
+		return -xt
+	}
+
+	return phiMinor(xt) / denom
+}
+
+// W is the multiplicative correction of the variance of a Gaussian truncated
+// at t, used by the Thurstone-Mosteller models for wins and losses
+func (u *Util) W(x, t float64) float64 {
+	xt := x - t
+	denom := phiMajor(xt)
+	if denom < machineEpsilon {
+		if x < 0 {
+			return 1.0
+		}
+		return 0.0
+	}
+
+	v := u.V(x, t)
+	return v * (v + xt)
+}
+
+// Vt is the additive correction of the mean of a Gaussian doubly truncated
+// between -t and t, used by the Thurstone-Mosteller models for draws
+func (u *Util) Vt(x, t float64) float64 {
+	xx := math.Abs(x)
+	b := phiMajor(t-xx) - phiMajor(-t-xx)
+	if b < 1e-5 {
+		if x < 0 {
+			return -x - t
+		}
+		return -x + t
+	}
+
+	a := phiMinor(-t-xx) - phiMinor(t-xx)
+	if x < 0 {
+		return -a / b
+	}
+	return a / b
+}
+
+// Wt is the multiplicative correction of the variance of a Gaussian doubly
+// truncated between -t and t, used by the Thurstone-Mosteller models for draws
+func (u *Util) Wt(x, t float64) float64 {
+	xx := math.Abs(x)
+	b := phiMajor(t-xx) - phiMajor(-t-xx)
+	if b < machineEpsilon {
+		return 1.0
+	}
+
+	vt := u.Vt(x, t)
+	return ((t-xx)*phiMinor(t-xx)+(t+xx)*phiMinor(-t-xx))/b + vt*vt
+}
+
+// LogSumQ returns the logarithm of SumQ for each team. It is computed with the
+// log-sum-exp trick, so it stays finite when exp(teamMu / c) would overflow.
+func (u *Util) LogSumQ(teamRatings []types.TeamRating, c float64) []float64 {
+	returning := make([]float64, len(teamRatings))
+
+	// Same walk as SumQ, from the worst rank to the best
+	groups := u.RankGroups(teamRatings)
+	logSum := math.Inf(-1)
+	for g := len(groups) - 1; g >= 0; g-- {
+		for _, i := range groups[g] {
+			logSum = u.LogAddExp(logSum, teamRatings[i].TeamMu/c)
 		}
-		for _, tr := range lowerRanked {
-			returning[i] += math.Exp(tr.TeamMu / c)
+		for _, i := range groups[g] {
+			returning[i] = logSum
 		}
 	}
 	return returning
 }
 
+// RankGroups returns the indexes of the teams grouped by rank, groups go from
+// the best rank to the worst and keep the original order of tied teams
+func (u *Util) RankGroups(teamRatings []types.TeamRating) [][]int {
+	order := make([]int, len(teamRatings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return teamRatings[order[a]].Rank < teamRatings[order[b]].Rank
+	})
+
+	var groups [][]int
+	for k, i := range order {
+		if k == 0 || teamRatings[i].Rank != teamRatings[order[k-1]].Rank {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], i)
+	}
+	return groups
+}
+
+// LogAddExp returns log(exp(a) + exp(b)) without overflowing
+func (u *Util) LogAddExp(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+// MarginFactor returns how much the mu update of a team is scaled after
+// scoring iScore against qScore in margin-of-victory mode, see
+// types.OpenSkillOptions.Margin. It is 1 when the difference is within the
+// margin or when margin is not positive.
+func (u *Util) MarginFactor(iScore, qScore, margin float64) float64 {
+	d := math.Abs(iScore - qScore)
+	if margin <= 0 || d <= margin {
+		return 1.0
+	}
+
+	return 1 + math.Log(d/margin)
+}
+
 // Rankings returns the normalized rankings of the teams
 func (u *Util) Rankings(teams []types.Team, ranks []int) []int {
 	if ranks == nil {
@@ -131,3 +331,17 @@ func (u *Util) Rankings(teams []types.Team, ranks []int) []int {
 
 	return returning
 }
+
+// machineEpsilon is the difference between 1.0 and the next representable
+// float64, below it the truncated Gaussian corrections become unstable
+const machineEpsilon = 2.220446049250313e-16
+
+// phiMajor is the CDF of the standard normal distribution
+func phiMajor(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
+
+// phiMinor is the PDF of the standard normal distribution
+func phiMinor(x float64) float64 {
+	return math.Exp(-0.5*x*x) / math.Sqrt(2*math.Pi)
+}
@@ -1,6 +1,7 @@
 package util_test
 
 import (
+	"math"
 	"testing"
 
 	_is "github.com/matryer/is"
@@ -8,6 +9,7 @@ import (
 	"github.com/intinig/go-openskill/models"
 	"github.com/intinig/go-openskill/rating"
 	"github.com/intinig/go-openskill/types"
+	"github.com/intinig/go-openskill/util"
 )
 
 // getTeam returns a team of n players all with the same rating
@@ -99,6 +101,36 @@ func TestTeamRatingAggregatesFiveVsFiveTeams(t *testing.T) {
 	})
 }
 
+func TestTeamRatingAppliesWeights(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	t1 := getTeam(2)
+	t2 := getTeam(1)
+
+	model := models.NewPlackettLuce(nil)
+	results := model.U.TeamRating([]types.Team{t1, t2}, &types.OpenSkillOptions{
+		Weight: [][]float64{{1, 0.5}, {0}},
+	})
+	is.Equal(results, []types.TeamRating{
+		{
+			TeamMu:           37.5,
+			TeamSigmaSquared: 86.80555555555557,
+			Team:             t1,
+			Rank:             0,
+			Weight:           []float64{1, 0.5},
+		},
+		{
+			TeamMu:           0,
+			TeamSigmaSquared: 0,
+			Team:             t2,
+			Rank:             1,
+			Weight:           []float64{0},
+		},
+	})
+}
+
 func TestUtilCComputations(t *testing.T) {
 	t.Parallel()
 
@@ -403,3 +435,144 @@ func TestUtilRankingsWithSparseScores(t *testing.T) {
 	ranks := model.U.Rankings([]types.Team{t1, t2, t3, t4, t5}, []int{14, 32, 47, 47, 48})
 	is.Equal(ranks, []int{0, 1, 2, 2, 4})
 }
+
+func TestUtilScore(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.Score(1, 2), 0.0)
+	is.Equal(model.U.Score(2, 1), 1.0)
+	is.Equal(model.U.Score(1, 1), 0.5)
+}
+
+func TestUtilScores(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	is.Equal(util.Scores(&types.OpenSkillOptions{}), nil)
+	is.Equal(util.Scores(&types.OpenSkillOptions{Score: []int{3, -1}}), []float64{3, -1})
+	is.Equal(util.Scores(&types.OpenSkillOptions{
+		Score:  []int{3, -1},
+		Scores: []float64{0.5, 1.25},
+	}), []float64{0.5, 1.25})
+}
+
+func TestUtilMarginFactor(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.MarginFactor(3, 1, 2), 1.0)
+	is.Equal(model.U.MarginFactor(1, 3, 2), 1.0)
+	is.Equal(model.U.MarginFactor(10, 0, 0), 1.0)
+	is.Equal(model.U.MarginFactor(0, 2*math.E, 2), 2.0)
+}
+
+func TestUtilLadderPairs(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	tr := model.U.TeamRating([]types.Team{getTeam(1), getTeam(2), getTeam(3)}, nil)
+
+	is.Equal(model.U.LadderPairs(nil), [][]types.TeamRating{})
+	is.Equal(model.U.LadderPairs(tr[:1]), [][]types.TeamRating{nil})
+	is.Equal(model.U.LadderPairs(tr), [][]types.TeamRating{
+		{tr[1]},
+		{tr[0], tr[2]},
+		{tr[1]},
+	})
+}
+
+func TestUtilV(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.V(1, 2), 1.525135276160981)
+	is.Equal(model.U.V(0, 2), 2.373215532822841)
+	is.Equal(model.U.V(0, -1), 0.2875999709391784)
+	is.Equal(model.U.V(-1000, 1000), 2000.0)
+}
+
+func TestUtilW(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.W(1, 2), 0.800902334429651)
+	is.Equal(model.U.W(0, 2), 0.8857208995859192)
+	is.Equal(model.U.W(0, -1), 0.3703137142233946)
+	is.Equal(model.U.W(-1000, 1000), 1.0)
+	is.Equal(model.U.W(1000, -1000), 0.0)
+}
+
+func TestUtilVt(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.Vt(1, 2), -0.28278611072715404)
+	is.Equal(model.U.Vt(0, 2), 0.0)
+	is.Equal(model.U.Vt(0, -1), -1.0)
+	is.Equal(model.U.Vt(-1000, 1000), 0.7978845608028654)
+	is.Equal(model.U.Vt(1000, -1000), -2000.0)
+}
+
+func TestUtilWt(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+	is.Equal(model.U.Wt(1, 2), 0.3838582646421707)
+	is.Equal(model.U.Wt(0, 2), 0.2262586964500768)
+	is.Equal(model.U.Wt(0, -1), 1.0)
+	is.Equal(model.U.Wt(-1000, 1000), 0.6366197723675814)
+	is.Equal(model.U.Wt(1000, -1000), 1.0)
+}
+
+func TestUtilLogSumQMatchesSumQ(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+
+	tr := model.U.TeamRating([]types.Team{getTeam(5), getTeam(5), getTeam(2)}, &types.OpenSkillOptions{
+		Rank: []int{2, 1, 2},
+	})
+	c := model.U.C(tr)
+	q := model.U.SumQ(tr, c)
+	logQ := model.U.LogSumQ(tr, c)
+
+	for i := range q {
+		is.True(math.Abs(math.Exp(logQ[i])-q[i]) < 1e-9)
+	}
+}
+
+func TestUtilLogSumQDoesNotOverflow(t *testing.T) {
+	t.Parallel()
+
+	is := _is.New(t)
+
+	model := models.NewPlackettLuce(nil)
+
+	big := types.Team{types.Rating{Mu: 1e6, Sigma: 1, Z: 3}}
+	tr := model.U.TeamRating([]types.Team{big, big}, nil)
+	c := model.U.C(tr)
+
+	is.True(math.IsInf(model.U.SumQ(tr, c)[0], 1))
+
+	logQ := model.U.LogSumQ(tr, c)
+	is.Equal(logQ[1], 1e6/c)
+	is.True(math.Abs(logQ[0]-(1e6/c+math.Ln2)) < 1e-9)
+}